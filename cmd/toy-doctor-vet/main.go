@@ -0,0 +1,19 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+// toy-doctor-vet run toy-doctor as a go vet tool
+//
+//	go vet -vettool=$(which toy-doctor-vet) ./...
+package main
+
+import (
+	"github.com/bigpigeon/toy-doctor/doctor"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(doctor.Analyzer)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer check toyorm brick chain, it can run with go vet -vettool, gopls or golangci-lint
+var Analyzer = &analysis.Analyzer{
+	Name: "toydoctor",
+	Doc: `check toyorm syntax error
+
+toydoctor check the field selection arguments of toyorm.ToyBrick chain
+methods, e.g toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{}.Name))
+use a field of Detail when brick model is Product.`,
+	Run: run,
+}
+
+// descriptor file of renamed toyorm api, see APIDescriptor
+var apiFile string
+
+// json file of extra drivers, see LoadDrivers
+var driversFile string
+
+// report the warnings too, they fail go vet like errors
+var reportWarnings bool
+
+func init() {
+	Analyzer.Flags.StringVar(&apiFile, "api", "", "toyorm api descriptor file for renamed methods")
+	Analyzer.Flags.StringVar(&driversFile, "drivers", "", "json file of extra database/sql drivers, driver name to the packages register it")
+	Analyzer.Flags.BoolVar(&reportWarnings, "warnings", false, "report the warnings, e.g driver not imported by the package or terminal operation in nested context")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	// package without toyorm have nothing to check
+	if !ImportsToyorm(pass.Pkg) {
+		return nil, nil
+	}
+	var api *APIDescriptor
+	if apiFile != "" {
+		var err error
+		if api, err = LoadAPIDescriptorFile(apiFile); err != nil {
+			return nil, err
+		}
+	}
+	// toyorm version in go.mod of the package
+	version := ""
+	if len(pass.Files) != 0 {
+		if gomod := findGoMod(filepath.Dir(pass.Fset.Position(pass.Files[0].Pos()).Filename)); gomod != "" {
+			version, _ = ModuleToyormVersion(gomod)
+		}
+	}
+	walk, err := newVersionWalker(pass.Fset, pass.Pkg, pass.Files, pass.TypesInfo, typesImporter(pass.Pkg), api, version, false)
+	if err != nil {
+		return nil, err
+	}
+	if driversFile != "" {
+		if walk.Drivers, err = LoadDriversFile(driversFile); err != nil {
+			return nil, err
+		}
+	}
+	walk.Walk()
+	for _, d := range walk.Diagnostics() {
+		pass.Report(analysis.Diagnostic{
+			Pos:            d.Pos(),
+			End:            d.End(),
+			Category:       d.Rule(),
+			Message:        d.Message(),
+			SuggestedFixes: walk.SuggestedFixes(d),
+		})
+	}
+	// warnings don't fail the check, go vet exit with failure on any diagnostic
+	if !reportWarnings {
+		return nil, nil
+	}
+	for _, d := range walk.Warnings() {
+		pass.Report(analysis.Diagnostic{
+			Pos:      d.Pos(),
+			End:      d.End(),
+			Category: d.Rule(),
+			Message:  d.Message(),
+		})
+	}
+	return nil, nil
+}
+
+// Diagnostics return all errors sorted by position
+func (w *Walker) Diagnostics() []Diagnostic {
+	var diags []Diagnostic
+	for expr, errs := range w.ErrorExpr {
+		for _, err := range errs {
+			diags = append(diags, toDiagnostic(expr, err))
+		}
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		if diags[i].Pos() != diags[j].Pos() {
+			return diags[i].Pos() < diags[j].Pos()
+		}
+		return diags[i].Rule() < diags[j].Rule()
+	})
+	return diags
+}
+
+// wrap the error without position to Diagnostic
+func toDiagnostic(expr ast.Expr, err error) Diagnostic {
+	if d, ok := err.(Diagnostic); ok {
+		return d
+	}
+	return exprError{expr, err}
+}
+
+// exprError wrap the error without position, e.g struct field map error
+type exprError struct {
+	Expr ast.Expr
+	Err  error
+}
+
+func (e exprError) Error() string   { return e.Err.Error() }
+func (e exprError) Rule() string    { return RuleError }
+func (e exprError) Pos() token.Pos  { return e.Expr.Pos() }
+func (e exprError) End() token.Pos  { return e.Expr.End() }
+func (e exprError) Message() string { return e.Err.Error() }
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+	"testing"
+)
+
+func runAnalyzer(t *testing.T, files ...string) []analysis.Diagnostic {
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax}
+	pkgs, err := packages.Load(cfg, files...)
+	assert.Nil(t, err)
+	assert.Equal(t, len(pkgs), 1)
+	pkg := pkgs[0]
+	assert.Empty(t, pkg.Errors)
+
+	var diags []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:  Analyzer,
+		Fset:      pkg.Fset,
+		Files:     pkg.Syntax,
+		Pkg:       pkg.Types,
+		TypesInfo: pkg.TypesInfo,
+		Report: func(d analysis.Diagnostic) {
+			diags = append(diags, d)
+		},
+	}
+	_, err = Analyzer.Run(pass)
+	assert.Nil(t, err)
+	for _, d := range diags {
+		t.Logf("%s: [%s] %s", pkg.Fset.Position(d.Pos), d.Category, d.Message)
+	}
+	return diags
+}
+
+func TestAnalyzer(t *testing.T) {
+	diags := runAnalyzer(t, "testdata/struct_notmatch.go")
+	categories := map[string]int{}
+	for _, d := range diags {
+		categories[d.Category]++
+	}
+	assert.Equal(t, categories[RuleInvalidField], 2)
+	assert.Equal(t, categories[RuleInvalidStructField], 3)
+	assert.True(t, categories[RuleDifferentStruct] > 0)
+}
+
+func TestAnalyzerWarnings(t *testing.T) {
+	// the mysql driver not imported is a warning, it isn't reported by default
+	categories := map[string]int{}
+	for _, d := range runAnalyzer(t, "testdata/driver.go") {
+		categories[d.Category]++
+	}
+	assert.Equal(t, categories[RuleUnknownDriver], 2)
+	assert.Equal(t, categories[RuleMissingDriver], 0)
+
+	assert.Nil(t, Analyzer.Flags.Set("warnings", "true"))
+	defer Analyzer.Flags.Set("warnings", "false")
+	categories = map[string]int{}
+	for _, d := range runAnalyzer(t, "testdata/driver.go") {
+		categories[d.Category]++
+	}
+	assert.Equal(t, categories[RuleMissingDriver], 1)
+}
+
+func TestImportsToyorm(t *testing.T) {
+	toyPkg := types.NewPackage(ToyormPath, "toyorm")
+	wrapper := types.NewPackage("example.com/shop/db", "db")
+	wrapper.SetImports([]*types.Package{toyPkg})
+	pkg := types.NewPackage("example.com/shop/model", "model")
+	// toyorm is imported through wrapper
+	pkg.SetImports([]*types.Package{wrapper})
+	assert.True(t, ImportsToyorm(pkg))
+	assert.False(t, ImportsToyorm(types.NewPackage("example.com/shop/util", "util")))
+}
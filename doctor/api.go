@@ -0,0 +1,216 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+)
+
+// APIDescriptor describe the toyorm api names, the api objects are resolved from the toyorm package
+// the analyzed program imported, use a descriptor file when the methods are renamed in your toyorm version
+type APIDescriptor struct {
+	// Toy method create brick, e.g toy.Model(&Product{})
+	Model string `json:"model"`
+	// brick methods push/pop the model stack
+	Preload string `json:"preload"`
+	Enter   string `json:"enter"`
+	Join    string `json:"join"`
+	Swap    string `json:"swap"`
+	// brick methods return a condition helper, e.g brick.Or().Condition(...)
+	Branch []string `json:"branch"`
+	// extra chain methods can't detect by signature
+	Chain []string `json:"chain"`
+	// extra methods operate records, e.g Find/Insert
+	Record []string `json:"record"`
+	// extra methods push the struct of first field selection to model stack like Preload
+	Push []string `json:"push"`
+	// extra methods pop the model stack like Enter
+	Pop []string `json:"pop"`
+}
+
+// DefaultAPI is the api names of toyorm
+var DefaultAPI = APIDescriptor{
+	Model:   "Model",
+	Preload: "Preload",
+	Enter:   "Enter",
+	Join:    "Join",
+	Swap:    "Swap",
+	Branch:  []string{"Or", "And"},
+}
+
+// LoadAPIDescriptor read the json descriptor, the missing names use DefaultAPI
+func LoadAPIDescriptor(in io.Reader) (*APIDescriptor, error) {
+	api := DefaultAPI
+	// decode json array reuse the slice, don't modify DefaultAPI
+	api.Branch = append([]string(nil), DefaultAPI.Branch...)
+	api.Chain = append([]string(nil), DefaultAPI.Chain...)
+	api.Record = append([]string(nil), DefaultAPI.Record...)
+	api.Push = append([]string(nil), DefaultAPI.Push...)
+	api.Pop = append([]string(nil), DefaultAPI.Pop...)
+	if err := json.NewDecoder(in).Decode(&api); err != nil {
+		return nil, fmt.Errorf("read api descriptor failure: %s", err)
+	}
+	return &api, nil
+}
+
+// LoadAPIDescriptorFile read the json descriptor file
+func LoadAPIDescriptorFile(filename string) (*APIDescriptor, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadAPIDescriptor(f)
+}
+
+func lookupNamed(pkg *types.Package, name string) (*types.Named, error) {
+	if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+		if named, ok := obj.Type().(*types.Named); ok {
+			return named, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s not found in %s", name, pkg.Path())
+}
+
+func lookupMethod(typ types.Type, name string) (*types.Func, error) {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+	if method, ok := obj.(*types.Func); ok {
+		return method, nil
+	}
+	return nil, fmt.Errorf("method %s of %s not found, use api descriptor for renamed method", name, typ)
+}
+
+// is the method return only one result with type typ
+func returnsOnly(method *types.Func, typ types.Type) bool {
+	results := method.Type().(*types.Signature).Results()
+	return results.Len() == 1 && types.Identical(results.At(0).Type(), typ)
+}
+
+func (w *Walker) IsPushMethod(obj types.Object) bool {
+	_, ok := w.ToyPushMethod[obj.String()]
+	return ok
+}
+
+func (w *Walker) IsPopMethod(obj types.Object) bool {
+	_, ok := w.ToyPopMethod[obj.String()]
+	return ok
+}
+
+// resolve the toyorm api objects from toyorm package imported by the analyzed program
+func (w *Walker) resolveAPI(api *APIDescriptor) error {
+	toyPkg, err := w.Importer.Import(ToyormPath)
+	if err != nil {
+		return err
+	}
+	toyType, err := lookupNamed(toyPkg, "Toy")
+	if err != nil {
+		return err
+	}
+	brickNamed, err := lookupNamed(toyPkg, "ToyBrick")
+	if err != nil {
+		return err
+	}
+	brickType := types.NewPointer(brickNamed)
+
+	if w.ToyModel, err = lookupMethod(types.NewPointer(toyType), api.Model); err != nil {
+		return err
+	}
+	// driver check is skipped when toyorm haven't Open
+	w.ToyOpen, _ = toyPkg.Scope().Lookup("Open").(*types.Func)
+	for _, m := range []struct {
+		name   string
+		method **types.Func
+		// Join/Swap is empty for the toyorm version haven't join
+		optional bool
+	}{
+		{api.Preload, &w.ToyChainPreload, false},
+		{api.Enter, &w.ToyChainEnter, false},
+		{api.Join, &w.ToyChainJoin, true},
+		{api.Swap, &w.ToyChainSwap, true},
+	} {
+		if m.optional && m.name == "" {
+			continue
+		}
+		if *m.method, err = lookupMethod(brickType, m.name); err != nil {
+			return err
+		}
+	}
+	params := w.ToyChainPreload.Type().(*types.Signature).Params()
+	if params.Len() == 0 {
+		return fmt.Errorf("%s have no field selection parameter", w.ToyChainPreload)
+	}
+	w.TypFieldSelection = params.At(0).Type()
+	w.TypOffsetof = types.Unsafe.Scope().Lookup("Offsetof").(*types.Builtin)
+	w.ConditionOperators = searchExprOperators(toyPkg)
+
+	// the methods push/pop the model stack
+	for _, m := range []struct {
+		names   []string
+		methods map[string]struct{}
+	}{
+		{append([]string{api.Preload, api.Join}, api.Push...), w.ToyPushMethod},
+		{append([]string{api.Enter, api.Swap}, api.Pop...), w.ToyPopMethod},
+	} {
+		for _, name := range m.names {
+			if name == "" {
+				continue
+			}
+			method, err := lookupMethod(brickType, name)
+			if err != nil {
+				return err
+			}
+			m.methods[method.String()] = struct{}{}
+		}
+	}
+
+	// all brick methods those return type are itself
+	mset := types.NewMethodSet(brickType)
+	for i := 0; i < mset.Len(); i++ {
+		method, ok := mset.At(i).Obj().(*types.Func)
+		if ok && !w.IsPushMethod(method) && !w.IsPopMethod(method) && returnsOnly(method, brickType) {
+			w.ToyChainMethod[method.String()] = struct{}{}
+		}
+	}
+	// condition helper, e.g brick.Or() and brick.Or().Condition(...)
+	for _, name := range api.Branch {
+		branch, err := lookupMethod(brickType, name)
+		if err != nil {
+			return err
+		}
+		w.ToyChainMethod[branch.String()] = struct{}{}
+		results := branch.Type().(*types.Signature).Results()
+		if results.Len() != 1 {
+			continue
+		}
+		branchSet := types.NewMethodSet(results.At(0).Type())
+		for i := 0; i < branchSet.Len(); i++ {
+			if method, ok := branchSet.At(i).Obj().(*types.Func); ok && returnsOnly(method, brickType) {
+				w.ToyChainMethod[method.String()] = struct{}{}
+			}
+		}
+	}
+	for _, name := range api.Chain {
+		method, err := lookupMethod(brickType, name)
+		if err != nil {
+			return err
+		}
+		w.ToyChainMethod[method.String()] = struct{}{}
+	}
+	w.cacheRecordMethods()
+	for _, name := range api.Record {
+		method, err := lookupMethod(brickType, name)
+		if err != nil {
+			return err
+		}
+		w.ToyRecordMethod[method.String()] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// toyorm with renamed Preload method
+const renamedToyormSrc = `
+package toyorm
+
+type FieldSelection interface{}
+
+type Toy struct{}
+
+func (t *Toy) Model(v interface{}) *ToyBrick { return nil }
+
+type ToyBrick struct{}
+
+type BrickOr struct{}
+
+func (t *ToyBrick) With(fv FieldSelection) *ToyBrick       { return t }
+func (t *ToyBrick) Enter() *ToyBrick                       { return t }
+func (t *ToyBrick) Join(fv FieldSelection) *ToyBrick       { return t }
+func (t *ToyBrick) Swap() *ToyBrick                        { return t }
+func (t *ToyBrick) OrderBy(vList ...FieldSelection) *ToyBrick { return t }
+func (t *ToyBrick) Or() BrickOr                            { return BrickOr{} }
+func (t *ToyBrick) And() BrickOr                           { return BrickOr{} }
+func (t *ToyBrick) Find(v interface{}) (interface{}, error) { return nil, nil }
+func (o BrickOr) Condition(expr string, key FieldSelection, v ...interface{}) *ToyBrick { return nil }
+`
+
+const renamedSrc = `
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID   uint32
+	Name string
+}
+
+type Product struct {
+	ID     uint32
+	Name   string
+	Detail Detail
+}
+
+func Renamed(toy *toyorm.Toy) {
+	_ = toy.Model(&Product{}).With(unsafe.Offsetof(Product{}.Detail)).OrderBy(unsafe.Offsetof(Product{}.Name))
+}
+`
+
+func renamedWalker(t *testing.T, api *APIDescriptor) (*Walker, error) {
+	return stubWalker(t, renamedToyormSrc, renamedSrc, api, "")
+}
+
+// type-check src with the toyorm source and create walker for toyorm version
+func stubWalker(t *testing.T, toyormSrc, src string, api *APIDescriptor, version string) (*Walker, error) {
+	fs := token.NewFileSet()
+	toyFile, err := parser.ParseFile(fs, "toyorm.go", toyormSrc, 0)
+	assert.Nil(t, err)
+	toyPkg, err := (&types.Config{}).Check(ToyormPath, fs, []*ast.File{toyFile}, nil)
+	assert.Nil(t, err)
+	imp := importerFunc(func(path string) (*types.Package, error) {
+		if path == ToyormPath {
+			return toyPkg, nil
+		}
+		return types.Unsafe, nil
+	})
+
+	file, err := parser.ParseFile(fs, "main.go", src, 0)
+	assert.Nil(t, err)
+	info := &types.Info{
+		Uses:       map[*ast.Ident]types.Object{},
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:     map[ast.Node]*types.Scope{},
+		Defs:       map[*ast.Ident]types.Object{},
+	}
+	pkg, err := (&types.Config{Importer: imp}).Check("main", fs, []*ast.File{file}, info)
+	assert.Nil(t, err)
+	return newVersionWalker(fs, pkg, []*ast.File{file}, info, imp, api, version, false)
+}
+
+func TestLoadAPIDescriptor(t *testing.T) {
+	api, err := LoadAPIDescriptor(strings.NewReader(`{"preload": "With", "record": ["Find"]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, api.Preload, "With")
+	assert.Equal(t, api.Model, DefaultAPI.Model)
+	assert.Equal(t, api.Branch, DefaultAPI.Branch)
+	assert.Equal(t, api.Record, []string{"Find"})
+
+	// DefaultAPI not be modified by descriptor
+	api, err = LoadAPIDescriptor(strings.NewReader(`{"branch": ["Either"]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, api.Branch, []string{"Either"})
+	assert.Equal(t, DefaultAPI.Branch, []string{"Or", "And"})
+
+	_, err = LoadAPIDescriptor(strings.NewReader(`{"preload": 1}`))
+	assert.NotNil(t, err)
+}
+
+func TestResolveRenamedAPI(t *testing.T) {
+	_, err := renamedWalker(t, nil)
+	assert.NotNil(t, err)
+	t.Log(err)
+
+	api, err := LoadAPIDescriptor(strings.NewReader(`{"preload": "With"}`))
+	assert.Nil(t, err)
+	walk, err := renamedWalker(t, api)
+	assert.Nil(t, err)
+	assert.Equal(t, walk.ToyChainPreload.Name(), "With")
+	assert.True(t, walk.IsPushMethod(walk.ToyChainPreload))
+	assert.True(t, walk.IsPopMethod(walk.ToyChainEnter))
+	assert.True(t, walk.IsBrickChain(walk.ToyChainEnter) == false)
+	assert.True(t, walk.IsRecordMethod(lookupMustMethod(t, walk, "Find")))
+	walk.Walk()
+	// OrderBy use Product field after With Detail
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleDifferentStruct], 1)
+}
+
+func lookupMustMethod(t *testing.T, walk *Walker, name string) types.Object {
+	brickType := walk.ToyChainPreload.Type().(*types.Signature).Recv().Type()
+	method, err := lookupMethod(brickType, name)
+	assert.Nil(t, err)
+	return method
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BaselineVersion is the version of baseline file schema
+const BaselineVersion = 1
+
+// BaselineEntry is the existing findings with same file, rule and fingerprint
+type BaselineEntry struct {
+	File        string `json:"file"`
+	Rule        string `json:"rule"`
+	Fingerprint string `json:"fingerprint"`
+	Count       int    `json:"count"`
+}
+
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+	// remaining count of each key when applied to walker
+	remain map[baselineKey]int
+}
+
+type baselineKey struct {
+	File, Rule, Fingerprint string
+}
+
+// get the outermost node with the same range of diagnostic
+func exactNode(files []*ast.File, pos, end token.Pos) ast.Node {
+	var found ast.Node
+	for _, file := range files {
+		if pos < file.Pos() || pos > file.End() {
+			continue
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			if found != nil || node == nil || node.End() < pos || node.Pos() > end {
+				return false
+			}
+			if node.Pos() == pos && node.End() == end {
+				found = node
+				return false
+			}
+			return true
+		})
+	}
+	return found
+}
+
+// name of function contain the pos, e.g ProductRepo.Find
+func enclosingFunc(files []*ast.File, pos token.Pos) string {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || pos < fn.Pos() || pos > fn.End() {
+				continue
+			}
+			if fn.Recv != nil && len(fn.Recv.List) != 0 {
+				return strings.TrimPrefix(types.ExprString(fn.Recv.List[0].Type), "*") + "." + fn.Name.Name
+			}
+			return fn.Name.Name
+		}
+	}
+	return ""
+}
+
+// the fingerprint not depend on position, so it is stable when the code is moved,
+// it is made up of rule, function name and the source of error expression without whitespace
+func (w *Walker) fingerprint(d Diagnostic) string {
+	src := d.Message()
+	if node := exactNode(w.Files, d.Pos(), d.End()); node != nil {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, w.FS, node); err == nil {
+			src = strings.Join(strings.Fields(buf.String()), "")
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{d.Rule(), enclosingFunc(w.Files, d.Pos()), src}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (w *Walker) baselineKey(d Diagnostic) baselineKey {
+	file := filepath.ToSlash(relPosition(w.FS, d.Pos()).Filename)
+	return baselineKey{file, d.Rule(), w.fingerprint(d)}
+}
+
+// WriteBaseline write all findings of walkers to baseline file, warnings are not included
+func WriteBaseline(out io.Writer, walkers []*Walker) error {
+	counts := map[baselineKey]int{}
+	for _, w := range walkers {
+		for _, d := range w.Diagnostics() {
+			counts[w.baselineKey(d)]++
+		}
+	}
+	baseline := Baseline{Version: BaselineVersion, Findings: []BaselineEntry{}}
+	for key, count := range counts {
+		baseline.Findings = append(baseline.Findings, BaselineEntry{key.File, key.Rule, key.Fingerprint, count})
+	}
+	sort.Slice(baseline.Findings, func(i, j int) bool {
+		a, b := baseline.Findings[i], baseline.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(baseline)
+}
+
+// ReadBaseline read the baseline file written by WriteBaseline
+func ReadBaseline(in io.Reader) (*Baseline, error) {
+	var baseline Baseline
+	if err := json.NewDecoder(in).Decode(&baseline); err != nil {
+		return nil, fmt.Errorf("read baseline failure: %s", err)
+	}
+	if baseline.Version != BaselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", baseline.Version)
+	}
+	baseline.remain = map[baselineKey]int{}
+	for _, e := range baseline.Findings {
+		baseline.remain[baselineKey{e.File, e.Rule, e.Fingerprint}] += e.Count
+	}
+	return &baseline, nil
+}
+
+// ApplyBaseline remove the findings recorded in baseline, only new findings will be reported,
+// the baseline can be shared by multiple walkers.
+// warnings are not recorded in baseline, they don't fail the check
+// and the unused ignore directive should be removed instead of hidden
+func (w *Walker) ApplyBaseline(baseline *Baseline) {
+	// the duplicate findings after recorded count are new
+	var exprs []ast.Expr
+	for expr := range w.ErrorExpr {
+		exprs = append(exprs, expr)
+	}
+	sort.Slice(exprs, func(i, j int) bool {
+		return exprs[i].Pos() < exprs[j].Pos()
+	})
+	for _, expr := range exprs {
+		var remain []error
+		for _, err := range w.ErrorExpr[expr] {
+			key := w.baselineKey(toDiagnostic(expr, err))
+			if baseline.remain[key] > 0 {
+				baseline.remain[key]--
+				w.BaselineCount++
+			} else {
+				remain = append(remain, err)
+			}
+		}
+		if len(remain) == 0 {
+			delete(w.ErrorExpr, expr)
+		} else {
+			w.ErrorExpr[expr] = remain
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func walkTestSource(t *testing.T, filename, src string) *Walker {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, filename, src, parser.ParseComments)
+	assert.Nil(t, err)
+	walk, err := NewWalker(fs, ".", []*ast.File{file}, false)
+	assert.Nil(t, err)
+	walk.Walk()
+	return walk
+}
+
+func TestBaseline(t *testing.T) {
+	const filename = "testdata/struct_notmatch.go"
+	data, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	src := string(data)
+
+	walk := walkTestSource(t, filename, src)
+	var buf bytes.Buffer
+	assert.Nil(t, WriteBaseline(&buf, []*Walker{walk}))
+	t.Log(buf.String())
+
+	baseline, err := ReadBaseline(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	total := 0
+	for _, e := range baseline.Findings {
+		assert.Equal(t, e.File, filename)
+		total += e.Count
+	}
+	assert.Equal(t, total, len(walk.Diagnostics()))
+
+	// code moved, all findings are in baseline
+	moved := strings.Replace(src, "func NotMatch() {", "\n\n\nfunc NotMatch() {\n", 1)
+	walk = walkTestSource(t, filename, moved)
+	baseline, err = ReadBaseline(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	walk.ApplyBaseline(baseline)
+	assert.Equal(t, len(walk.Diagnostics()), 0)
+	assert.Equal(t, walk.BaselineCount, total)
+
+	// new finding
+	added := strings.Replace(src, "\t// normal\n", "\t// normal\n\t_ = toy.Model(&Product{}).OrderBy(\"NewError\")\n", 1)
+	walk = walkTestSource(t, filename, added)
+	baseline, err = ReadBaseline(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	walk.ApplyBaseline(baseline)
+	diags := walk.Diagnostics()
+	if assert.Equal(t, len(diags), 1) {
+		assert.Equal(t, diags[0].Rule(), RuleInvalidField)
+	}
+
+	_, err = ReadBaseline(strings.NewReader(`{"version": 100}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,377 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	RuleInvalidValue    = "invalid-value"
+	RuleInvalidOperator = "invalid-operator"
+)
+
+// condition value not match the selected field
+type ErrInvalidValue struct {
+	FileSet *token.FileSet
+	Expr    ast.Expr
+	Msg     string
+}
+
+func (e ErrInvalidValue) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Msg)
+}
+
+func (e ErrInvalidValue) Rule() string    { return RuleInvalidValue }
+func (e ErrInvalidValue) Pos() token.Pos  { return e.Expr.Pos() }
+func (e ErrInvalidValue) End() token.Pos  { return e.Expr.End() }
+func (e ErrInvalidValue) Message() string { return e.Msg }
+
+// unknown condition operator or operator with wrong number of values
+type ErrInvalidOperator struct {
+	FileSet *token.FileSet
+	Expr    ast.Expr
+	Msg     string
+}
+
+func (e ErrInvalidOperator) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Msg)
+}
+
+func (e ErrInvalidOperator) Rule() string    { return RuleInvalidOperator }
+func (e ErrInvalidOperator) Pos() token.Pos  { return e.Expr.Pos() }
+func (e ErrInvalidOperator) End() token.Pos  { return e.Expr.End() }
+func (e ErrInvalidOperator) Message() string { return e.Msg }
+
+type conditionValueKind int
+
+const (
+	// one value with field type
+	conditionSingle conditionValueKind = iota
+	// one slice value with field element type
+	conditionSlice
+	// two value with field type
+	conditionPair
+	// without value
+	conditionNone
+)
+
+// value kind of condition operator, the operator not in it accept 1 value
+var conditionOperatorKinds = map[string]conditionValueKind{
+	"IN":          conditionSlice,
+	"NOT IN":      conditionSlice,
+	"BETWEEN":     conditionPair,
+	"NOT BETWEEN": conditionPair,
+	"NULL":        conditionNone,
+	"NOT NULL":    conditionNone,
+}
+
+// the operators used when toyorm haven't SearchExpr constants
+var defaultConditionOperators = []string{
+	"=", "<>", ">", ">=", "<", "<=", "LIKE", "NOT LIKE", "IN", "NOT IN", "BETWEEN", "NOT BETWEEN", "NULL", "NOT NULL",
+}
+
+// the SearchExpr constants those aren't condition operator, e.g ExprAnd/ExprOr join conditions
+var conditionLogicOperators = map[string]struct{}{
+	"":    {},
+	"AND": {},
+	"OR":  {},
+}
+
+// get the condition operators from Expr* constants of toyorm SearchExpr, e.g ExprEqual, ExprIn
+func searchExprOperators(toyPkg *types.Package) map[string]conditionValueKind {
+	operators := map[string]conditionValueKind{}
+	add := func(op string) {
+		if _, ok := conditionLogicOperators[op]; ok {
+			return
+		}
+		operators[op] = conditionOperatorKinds[op]
+	}
+	if searchExpr, err := lookupNamed(toyPkg, "SearchExpr"); err == nil {
+		scope := toyPkg.Scope()
+		for _, name := range scope.Names() {
+			c, ok := scope.Lookup(name).(*types.Const)
+			if ok && strings.HasPrefix(name, "Expr") && types.Identical(c.Type(), searchExpr) && c.Val().Kind() == constant.String {
+				add(constant.StringVal(c.Val()))
+			}
+		}
+	}
+	if len(operators) == 0 {
+		for _, op := range defaultConditionOperators {
+			add(op)
+		}
+	}
+	return operators
+}
+
+// the number of values operator accepted
+func (k conditionValueKind) accept(n int) bool {
+	switch k {
+	case conditionNone:
+		return n == 0
+	case conditionPair:
+		return n == 1 || n == 2
+	default:
+		return n == 1
+	}
+}
+
+func (k conditionValueKind) String() string {
+	switch k {
+	case conditionNone:
+		return "no value"
+	case conditionPair:
+		return "2 values or a slice with 2 elements"
+	case conditionSlice:
+		return "1 slice value"
+	default:
+		return "1 value"
+	}
+}
+
+func (w *Walker) supportedOperators() string {
+	var ops []string
+	for op := range w.ConditionOperators {
+		ops = append(ops, fmt.Sprintf("%q", op))
+	}
+	sort.Strings(ops)
+	return strings.Join(ops, ", ")
+}
+
+// ConditionArgs is the arguments of Where/Condition, e.g Where("=", Offsetof(Product{}.Name), "pigeon")
+type ConditionArgs struct {
+	Operator ast.Expr
+	Key      ast.Expr
+	Values   []ast.Expr
+}
+
+// get condition args by signature (expr SearchExpr, key FieldSelection, v ...interface{})
+func (w *Walker) conditionArgs(call *ast.CallExpr) (ConditionArgs, bool) {
+	sign, ok := w.Info.Types[call.Fun].Type.(*types.Signature)
+	if ok == false || sign.Variadic() == false {
+		return ConditionArgs{}, false
+	}
+	params := sign.Params()
+	k := params.Len() - 2
+	if k < 1 || params.At(k).Type().String() != w.TypFieldSelection.String() {
+		return ConditionArgs{}, false
+	}
+	if basic, ok := params.At(k - 1).Type().Underlying().(*types.Basic); ok == false || basic.Kind() != types.String {
+		return ConditionArgs{}, false
+	}
+	if len(call.Args) <= k || call.Ellipsis != token.NoPos {
+		return ConditionArgs{}, false
+	}
+	return ConditionArgs{call.Args[k-1], call.Args[k], call.Args[k+1:]}, true
+}
+
+// the operator of condition, ok is false when it isn't a constant,
+// it isn't normalized because toyorm only accept the exact SearchExpr value
+func (w *Walker) conditionOperator(expr ast.Expr) (string, bool) {
+	if tv, ok := w.Info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value), true
+	}
+	return "", false
+}
+
+// get the field that key selected in model
+func (w *Walker) selectedField(mType *types.Named, key ast.Expr) *types.Var {
+	switch x := key.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return nil
+		}
+		fieldMap, err := getStructFieldMap(mType.Underlying().(*types.Struct), w.FS)
+		if err != nil {
+			return nil
+		}
+		name, err := strconv.Unquote(x.Value)
+		if err != nil {
+			return nil
+		}
+		return fieldMap[name]
+	case *ast.CallExpr:
+		if w.isOffsetof(x) == false {
+			return nil
+		}
+		arg, ok := x.Args[0].(*ast.SelectorExpr)
+		if ok == false || getTypesStruct(w.Info.Types[arg.X].Type) != mType {
+			return nil
+		}
+		if selection := w.Info.Selections[arg]; selection != nil {
+			field, _ := selection.Obj().(*types.Var)
+			return field
+		}
+	}
+	return nil
+}
+
+// is call unsafe.Offsetof
+func (w *Walker) isOffsetof(call *ast.CallExpr) bool {
+	var obj types.Object
+	switch y := call.Fun.(type) {
+	case *ast.Ident:
+		obj = w.Info.Uses[y]
+	case *ast.SelectorExpr:
+		obj = w.Info.Uses[y.Sel]
+	}
+	return obj != nil && obj.String() == w.TypOffsetof.String() && len(call.Args) == 1
+}
+
+// check the operator and value of condition, value must match the selected field type
+// e.g
+// Where("=", Offsetof(Product{}.Name), "pigeon")  ........ ok
+// Where("==", Offsetof(Product{}.Name), "pigeon")  ....... error, unknown operator
+// Where("like", Offsetof(Product{}.Name), "pigeon")  ..... error, toyorm operator is LIKE
+// Where("=", Offsetof(Product{}.Name), 42)  .............. error, Name is string
+// Where("IN", Offsetof(Product{}.Name), "pigeon")  ....... error, IN need a slice
+func (w *Walker) checkCondition(call *ast.CallExpr, mType *types.Named) {
+	args, ok := w.conditionArgs(call)
+	if ok == false {
+		return
+	}
+	op, ok := w.conditionOperator(args.Operator)
+	if ok == false {
+		return
+	}
+	w.markExpr(args.Operator)
+	w.CheckedExpr[args.Operator] = struct{}{}
+	kind, ok := w.ConditionOperators[op]
+	if ok == false {
+		w.addOperatorError(args.Operator, fmt.Sprintf("unknown operator %q, supported operators: %s", op, w.supportedOperators()))
+		return
+	}
+	if kind.accept(len(args.Values)) == false {
+		w.addOperatorError(args.Operator, fmt.Sprintf("%s need %s but got %d", op, kind, len(args.Values)))
+		return
+	}
+	// model is unknown
+	if mType == nil {
+		return
+	}
+	field := w.selectedField(mType, args.Key)
+	if field == nil {
+		return
+	}
+	w.markExpr(args.Values...)
+	switch kind {
+	case conditionSingle:
+		for _, v := range args.Values {
+			w.checkConditionValue(v, field.Type(), field)
+		}
+	case conditionSlice:
+		for _, v := range args.Values {
+			w.CheckedExpr[v] = struct{}{}
+			vType := w.Info.TypeOf(v)
+			if elem := sliceElem(vType); elem != nil {
+				w.checkValueType(v, elem, field.Type(), field)
+			} else if isInterface(vType) == false {
+				w.addValueError(v, fmt.Sprintf("%s need a slice value but it's %s", op, vType))
+			}
+		}
+	case conditionPair:
+		if len(args.Values) == 1 {
+			// pair in a slice or array
+			v := args.Values[0]
+			w.CheckedExpr[v] = struct{}{}
+			vType := w.Info.TypeOf(v)
+			// wrong number of values is operator error like BETWEEN with 3 values
+			if arr, ok := vType.Underlying().(*types.Array); ok && arr.Len() != 2 {
+				w.addOperatorError(v, fmt.Sprintf("%s need %s but got %d", op, kind, arr.Len()))
+			} else if elem := sliceElem(vType); elem != nil {
+				w.checkValueType(v, elem, field.Type(), field)
+			} else if isInterface(vType) == false {
+				w.addOperatorError(v, fmt.Sprintf("%s need %s but got 1", op, kind))
+			}
+		} else {
+			for _, v := range args.Values {
+				w.checkConditionValue(v, field.Type(), field)
+			}
+		}
+	}
+}
+
+func (w *Walker) checkConditionValue(v ast.Expr, fieldType types.Type, field *types.Var) {
+	w.CheckedExpr[v] = struct{}{}
+	w.checkValueType(v, w.Info.TypeOf(v), fieldType, field)
+}
+
+func (w *Walker) checkValueType(v ast.Expr, vType, fieldType types.Type, field *types.Var) {
+	if valueCompatible(vType, fieldType) == false {
+		w.addValueError(v, fmt.Sprintf("value type %s not match field %s type %s", vType, field.Name(), fieldType))
+	}
+}
+
+func (w *Walker) addValueError(expr ast.Expr, msg string) {
+	w.CheckedExpr[expr] = struct{}{}
+	w.ErrorExpr[expr] = append(w.ErrorExpr[expr], ErrInvalidValue{w.FS, expr, msg})
+}
+
+func (w *Walker) addOperatorError(expr ast.Expr, msg string) {
+	w.ErrorExpr[expr] = append(w.ErrorExpr[expr], ErrInvalidOperator{w.FS, expr, msg})
+}
+
+func sliceElem(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	switch x := t.Underlying().(type) {
+	case *types.Slice:
+		return x.Elem()
+	case *types.Array:
+		return x.Elem()
+	}
+	return nil
+}
+
+func isInterface(t types.Type) bool {
+	if t == nil {
+		return true
+	}
+	_, ok := t.Underlying().(*types.Interface)
+	return ok
+}
+
+// can value be compare with field in sql, only basic type are checked
+func valueCompatible(vType, fieldType types.Type) bool {
+	if vType == nil || fieldType == nil {
+		return true
+	}
+	if ptr, ok := vType.(*types.Pointer); ok {
+		vType = ptr.Elem()
+	}
+	if ptr, ok := fieldType.(*types.Pointer); ok {
+		fieldType = ptr.Elem()
+	}
+	if types.AssignableTo(vType, fieldType) {
+		return true
+	}
+	vBasic, ok1 := vType.Underlying().(*types.Basic)
+	fBasic, ok2 := fieldType.Underlying().(*types.Basic)
+	if ok1 == false || ok2 == false || vBasic.Kind() == types.UntypedNil {
+		return true
+	}
+	class := func(b *types.Basic) types.BasicInfo {
+		switch {
+		case b.Info()&types.IsString != 0:
+			return types.IsString
+		case b.Info()&types.IsNumeric != 0:
+			return types.IsNumeric
+		case b.Info()&types.IsBoolean != 0:
+			return types.IsBoolean
+		}
+		return 0
+	}
+	return class(vBasic) == class(fBasic)
+}
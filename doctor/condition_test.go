@@ -0,0 +1,32 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/types"
+	"testing"
+)
+
+func TestValueCompatible(t *testing.T) {
+	assert.True(t, valueCompatible(types.Typ[types.UntypedInt], types.Typ[types.Uint32]))
+	assert.True(t, valueCompatible(types.Typ[types.Int], types.NewPointer(types.Typ[types.Float64])))
+	assert.True(t, valueCompatible(types.Typ[types.String], types.Typ[types.String]))
+	assert.False(t, valueCompatible(types.Typ[types.UntypedInt], types.Typ[types.String]))
+	assert.False(t, valueCompatible(types.Typ[types.Bool], types.Typ[types.Int]))
+}
+
+func TestWalkCondition(t *testing.T) {
+	walk := walkTestFile(t, "testdata/condition.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleInvalidValue], 6)
+	assert.Equal(t, rules[RuleInvalidOperator], 10)
+	// operators are read from toyorm SearchExpr constants
+	assert.Equal(t, len(walk.ConditionOperators), 14)
+	assert.Equal(t, walk.ConditionOperators["NOT IN"], conditionSlice)
+	assert.NotContains(t, walk.ConditionOperators, "AND")
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const RuleDiscardedResult = "discarded-result"
+
+// brick chain method return a new brick, the statement discard it do nothing
+type ErrDiscardedResult struct {
+	FileSet *token.FileSet
+	// *ast.ExprStmt or the assignment to _
+	Stmt   ast.Stmt
+	Call   *ast.CallExpr
+	Method types.Object
+	// the brick variable the chain start from and read after the statement, the result should assign to it
+	Brick *ast.Ident
+}
+
+func (e ErrDiscardedResult) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Call.Pos()), e.Message())
+}
+
+func (e ErrDiscardedResult) Rule() string   { return RuleDiscardedResult }
+func (e ErrDiscardedResult) Pos() token.Pos { return e.Call.Pos() }
+func (e ErrDiscardedResult) End() token.Pos { return e.Call.End() }
+func (e ErrDiscardedResult) Message() string {
+	return fmt.Sprintf("result of %s is discarded, it return a new brick", e.Method.Name())
+}
+
+// the brick variable of chain, e.g brick in brick.Preload(...).OrderBy(...)
+func chainRoot(expr ast.Expr) ast.Expr {
+	for {
+		switch x := expr.(type) {
+		case *ast.ParenExpr:
+			expr = x.X
+		case *ast.CallExpr:
+			sel, ok := x.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return x
+			}
+			expr = sel.X
+		default:
+			return x
+		}
+	}
+}
+
+// brick chain method return a new *ToyBrick, e.g OrderBy/Preload/Enter and brick.Or().Condition(...),
+// Toy.Model and the condition helper Or/And aren't
+func (w *Walker) isBrickResultMethod(obj types.Object) bool {
+	method, ok := obj.(*types.Func)
+	if !ok || !(w.IsBrickChain(method) || w.IsPushMethod(method) || w.IsPopMethod(method)) {
+		return false
+	}
+	results := method.Type().(*types.Signature).Results()
+	return results.Len() == 1 && w.isBrickType(results.At(0).Type())
+}
+
+// is the variable read after pos, assign to it isn't read
+func (w *Walker) readAfter(v *types.Var, pos token.Pos) bool {
+	for _, file := range w.Files {
+		if pos < file.Pos() || pos > file.End() {
+			continue
+		}
+		writes := map[*ast.Ident]bool{}
+		read := false
+		ast.Inspect(file, func(node ast.Node) bool {
+			if read {
+				return false
+			}
+			switch x := node.(type) {
+			case *ast.AssignStmt:
+				if x.Tok == token.ASSIGN || x.Tok == token.DEFINE {
+					for _, lh := range x.Lhs {
+						if ident, ok := lh.(*ast.Ident); ok {
+							writes[ident] = true
+						}
+					}
+				}
+			case *ast.Ident:
+				if x.Pos() > pos && !writes[x] && w.Info.Uses[x] == v {
+					read = true
+				}
+			}
+			return true
+		})
+		return read
+	}
+	return false
+}
+
+// for the statements
+// brick.OrderBy(...) ....... error, the new brick is never used
+// _ = brick.OrderBy(...) ... error, brick is a variable
+func (w *Walker) checkDiscarded(stmt ast.Stmt) {
+	var expr ast.Expr
+	switch x := stmt.(type) {
+	case *ast.ExprStmt:
+		expr = x.X
+	case *ast.AssignStmt:
+		if len(x.Lhs) != 1 || len(x.Rhs) != 1 {
+			return
+		}
+		if ident, ok := x.Lhs[0].(*ast.Ident); !ok || ident.Name != "_" {
+			return
+		}
+		expr = x.Rhs[0]
+	default:
+		return
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	method := w.Info.Uses[sel.Sel]
+	if method == nil || !w.isBrickResultMethod(method) {
+		return
+	}
+	e := ErrDiscardedResult{FileSet: w.FS, Stmt: stmt, Call: call, Method: method}
+	root, _ := chainRoot(call).(*ast.Ident)
+	v, _ := w.Info.Uses[root].(*types.Var)
+	if v == nil || !w.isBrickType(v.Type()) {
+		// _ = toy.Model(...).OrderBy(...) is the explicit discard of a new brick,
+		// but the brick variable is expected to be changed in _ = brick.OrderBy(...)
+		if _, ok := stmt.(*ast.AssignStmt); ok {
+			return
+		}
+	} else if w.readAfter(v, stmt.End()) {
+		e.Brick = root
+	}
+	w.CheckedExpr[call] = struct{}{}
+	w.ErrorExpr[call] = append(w.ErrorExpr[call], e)
+}
+
+// brick.OrderBy(...) to brick = brick.OrderBy(...)
+// _ = brick.OrderBy(...) to brick = brick.OrderBy(...)
+func (e ErrDiscardedResult) suggestedFixes() []analysis.SuggestedFix {
+	if e.Brick == nil {
+		return nil
+	}
+	var edit analysis.TextEdit
+	switch x := e.Stmt.(type) {
+	case *ast.ExprStmt:
+		edit = analysis.TextEdit{Pos: x.Pos(), End: x.Pos(), NewText: []byte(e.Brick.Name + " = ")}
+	case *ast.AssignStmt:
+		edit = analysis.TextEdit{Pos: x.Lhs[0].Pos(), End: x.Lhs[0].End(), NewText: []byte(e.Brick.Name)}
+	}
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("assign the result to %s", e.Brick.Name),
+		TextEdits: []analysis.TextEdit{edit},
+	}}
+}
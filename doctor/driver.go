@@ -0,0 +1,212 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	RuleUnknownDriver = "unknown-driver"
+	RuleMissingDriver = "missing-driver"
+)
+
+// DefaultDrivers is the database/sql drivers toyorm supported, driver name to the packages register it
+var DefaultDrivers = map[string][]string{
+	"sqlite3":  {"github.com/mattn/go-sqlite3"},
+	"mysql":    {"github.com/go-sql-driver/mysql"},
+	"postgres": {"github.com/lib/pq"},
+}
+
+// LoadDrivers read the json drivers file e.g {"sqlite3": ["github.com/mattn/go-sqlite3"]},
+// the drivers in file replace the same name of DefaultDrivers
+func LoadDrivers(in io.Reader) (map[string][]string, error) {
+	var drivers map[string][]string
+	if err := json.NewDecoder(in).Decode(&drivers); err != nil {
+		return nil, fmt.Errorf("read drivers failure: %s", err)
+	}
+	merged := map[string][]string{}
+	for name, pkgs := range DefaultDrivers {
+		merged[name] = pkgs
+	}
+	for name, pkgs := range drivers {
+		merged[name] = pkgs
+	}
+	return merged, nil
+}
+
+// LoadDriversFile read the json drivers file
+func LoadDriversFile(filename string) (map[string][]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadDrivers(f)
+}
+
+// driver name of toyorm.Open not in known drivers
+type ErrUnknownDriver struct {
+	FileSet *token.FileSet
+	Expr    ast.Expr
+	Name    string
+	// the known driver names close to Name
+	Suggestions []string
+}
+
+func (e ErrUnknownDriver) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Message())
+}
+
+func (e ErrUnknownDriver) Rule() string   { return RuleUnknownDriver }
+func (e ErrUnknownDriver) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrUnknownDriver) End() token.Pos { return e.Expr.End() }
+func (e ErrUnknownDriver) Message() string {
+	return fmt.Sprintf("unknown driver %q%s", e.Name, didYouMean(e.Suggestions))
+}
+
+// no package register the driver is imported
+type ErrMissingDriver struct {
+	FileSet  *token.FileSet
+	Expr     ast.Expr
+	Name     string
+	Packages []string
+}
+
+func (e ErrMissingDriver) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Message())
+}
+
+func (e ErrMissingDriver) Rule() string   { return RuleMissingDriver }
+func (e ErrMissingDriver) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrMissingDriver) End() token.Pos { return e.Expr.End() }
+func (e ErrMissingDriver) Message() string {
+	var imports []string
+	for _, pkg := range e.Packages {
+		imports = append(imports, "_ "+strconv.Quote(pkg))
+	}
+	return fmt.Sprintf("driver %q is not registered, import %s", e.Name, strings.Join(imports, " or "))
+}
+
+// is the package or its dependencies import any of paths
+func importsAny(pkg *types.Package, paths []string) bool {
+	want := map[string]bool{}
+	for _, path := range paths {
+		want[path] = true
+	}
+	visited := map[*types.Package]bool{}
+	var visit func(pkg *types.Package) bool
+	visit = func(pkg *types.Package) bool {
+		if visited[pkg] {
+			return false
+		}
+		visited[pkg] = true
+		for _, imp := range pkg.Imports() {
+			if want[imp.Path()] || visit(imp) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(pkg)
+}
+
+// ProgramImports return the import paths of main packages in pkgs and their dependencies,
+// nil when there isn't main package, the driver may be imported by the program use the packages.
+// the packages not in it aren't part of the program, e.g a library only imported by tests
+func ProgramImports(pkgs []*packages.Package) map[string]bool {
+	var mains []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg.Name == "main" {
+			mains = append(mains, pkg)
+		}
+	}
+	if len(mains) == 0 {
+		return nil
+	}
+	imports := map[string]bool{}
+	packages.Visit(mains, func(pkg *packages.Package) bool {
+		imports[pkg.PkgPath] = true
+		return true
+	}, nil)
+	return imports
+}
+
+func containsAny(set map[string]bool, paths []string) bool {
+	for _, path := range paths {
+		if set[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// check the constant driver name of toyorm.Open
+// e.g
+// toyorm.Open("sqlite3", "") ....... ok, github.com/mattn/go-sqlite3 is imported
+// toyorm.Open("sqlit3", "") ........ error, unknown driver
+// the driver not imported is error when the package is part of the program walker know, otherwise it is a warning
+func (w *Walker) checkDriver(call *ast.CallExpr) {
+	if w.ToyOpen == nil || len(call.Args) == 0 {
+		return
+	}
+	var obj types.Object
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		obj = w.Info.Uses[fun.Sel]
+	case *ast.Ident:
+		obj = w.Info.Uses[fun]
+	}
+	if obj == nil || obj.String() != w.ToyOpen.String() {
+		return
+	}
+	arg := call.Args[0]
+	w.markExpr(arg)
+	tv, ok := w.Info.Types[arg]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	w.CheckedExpr[arg] = struct{}{}
+	name := constant.StringVal(tv.Value)
+	pkgs, ok := w.Drivers[name]
+	switch {
+	case !ok:
+		var known []string
+		for driver := range w.Drivers {
+			known = append(known, driver)
+		}
+		sort.Strings(known)
+		suggestions := closestNames(name, known)
+		if len(suggestions) > maxSuggestions {
+			suggestions = suggestions[:maxSuggestions]
+		}
+		w.ErrorExpr[arg] = append(w.ErrorExpr[arg], ErrUnknownDriver{w.FS, arg, name, suggestions})
+	case len(pkgs) == 0:
+		// empty packages skip the import check
+	case importsAny(w.Pkg, pkgs):
+		// the package itself register the driver
+	case w.ProgramImports[w.Pkg.Path()]:
+		if !containsAny(w.ProgramImports, pkgs) {
+			w.ErrorExpr[arg] = append(w.ErrorExpr[arg], ErrMissingDriver{w.FS, arg, name, pkgs})
+		}
+	default:
+		// the driver may be imported by the main package use this package
+		w.WarningExpr[arg] = append(w.WarningExpr[arg], ErrMissingDriver{w.FS, arg, name, pkgs})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestWalkDriver(t *testing.T) {
+	walk := walkTestFile(t, "testdata/driver.go")
+	var errs, fixes []string
+	for _, d := range walk.Diagnostics() {
+		errs = append(errs, d.Error())
+		for _, fix := range walk.SuggestedFixes(d) {
+			fixes = append(fixes, walk.FS.Position(fix.TextEdits[0].Pos).String()+" "+string(fix.TextEdits[0].NewText))
+		}
+	}
+	assert.Equal(t, errs, []string{
+		`testdata/driver.go:17:21 unknown driver "sqlit3", did you mean "sqlite3"?`,
+		`testdata/driver.go:18:21 unknown driver "oracle"`,
+	})
+	assert.Equal(t, fixes, []string{`testdata/driver.go:17:21 "sqlite3"`})
+	// the program is unknown, main package may import the driver
+	var warnings []string
+	for _, d := range walk.Warnings() {
+		warnings = append(warnings, d.Error())
+	}
+	assert.Equal(t, warnings, []string{
+		`testdata/driver.go:20:21 driver "mysql" is not registered, import _ "github.com/go-sql-driver/mysql"`,
+	})
+}
+
+func TestWalkProgramDriver(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "testdata/driver.go", nil, parser.ParseComments)
+	assert.Nil(t, err)
+	walk, err := NewWalker(fs, ".", []*ast.File{file}, false)
+	assert.Nil(t, err)
+	// main package of program haven't import mysql driver
+	walk.ProgramImports = map[string]bool{walk.Pkg.Path(): true, ToyormPath: true}
+	walk.Walk()
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleMissingDriver], 1)
+	assert.Equal(t, len(walk.Warnings()), 0)
+
+	walk.ProgramImports = map[string]bool{walk.Pkg.Path(): true, ToyormPath: true, "github.com/go-sql-driver/mysql": true}
+	walk.Walk()
+	rules = diagnosticRules(walk)
+	assert.Equal(t, rules[RuleMissingDriver], 0)
+
+	// the package isn't imported by main packages, only a warning
+	walk.ProgramImports = map[string]bool{ToyormPath: true}
+	walk.Walk()
+	rules = diagnosticRules(walk)
+	assert.Equal(t, rules[RuleMissingDriver], 0)
+	assert.Equal(t, len(walk.Warnings()), 1)
+
+	// time is imported by the package itself through toyorm
+	walk.ProgramImports = map[string]bool{walk.Pkg.Path(): true, ToyormPath: true}
+	walk.Drivers, err = LoadDrivers(strings.NewReader(`{"mysql": ["time"]}`))
+	assert.Nil(t, err)
+	walk.Walk()
+	rules = diagnosticRules(walk)
+	assert.Equal(t, rules[RuleMissingDriver], 0)
+	assert.Equal(t, len(walk.Warnings()), 0)
+}
+
+func TestWalkCustomDriver(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "testdata/driver.go", nil, parser.ParseComments)
+	assert.Nil(t, err)
+	walk, err := NewWalker(fs, ".", []*ast.File{file}, false)
+	assert.Nil(t, err)
+	// time is imported by toyorm, empty packages skip the import check
+	walk.Drivers, err = LoadDrivers(strings.NewReader(`{"mysql": ["time"], "oracle": []}`))
+	assert.Nil(t, err)
+	walk.Walk()
+	var rules []string
+	for _, d := range walk.Diagnostics() {
+		rules = append(rules, d.Rule())
+	}
+	assert.Equal(t, rules, []string{RuleUnknownDriver})
+	// default drivers are kept
+	assert.Equal(t, walk.Drivers["sqlite3"], DefaultDrivers["sqlite3"])
+}
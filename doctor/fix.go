@@ -0,0 +1,206 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// edit distance of two string, transposition of two adjacent characters is 1 edit
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// distance of misspelled name, case difference is cheaper than other edits,
+// return -1 when there are more than 1 edit per 3 characters
+func nameDistance(name, candidate string) int {
+	maxDist := len(name) / 3
+	if maxDist < 1 {
+		maxDist = 1
+	}
+	dist := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+	if dist > maxDist {
+		return -1
+	}
+	return dist*2 + b2i(levenshtein(name, candidate) != dist)
+}
+
+// the names close to name sorted by distance
+func closestNames(name string, candidates []string) []string {
+	dists := map[string]int{}
+	var names []string
+	for _, c := range candidates {
+		if dist := nameDistance(name, c); dist != -1 {
+			dists[c] = dist
+			names = append(names, c)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if dists[names[i]] != dists[names[j]] {
+			return dists[names[i]] < dists[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// the only closest field name of model
+func (w *Walker) closestField(name string, model *types.Named) (string, bool) {
+	fieldMap, err := getStructFieldMap(model.Underlying().(*types.Struct), w.FS)
+	if err != nil {
+		return "", false
+	}
+	var candidates []string
+	for k := range fieldMap {
+		candidates = append(candidates, k)
+	}
+	names := closestNames(name, candidates)
+	if len(names) == 0 {
+		return "", false
+	}
+	// not sure which one is right
+	if len(names) > 1 && nameDistance(name, names[0]) == nameDistance(name, names[1]) {
+		return "", false
+	}
+	return names[0], true
+}
+
+// the name of named type used in file contain expr, e.g Product or model.Product
+func (w *Walker) typeName(named *types.Named, expr ast.Expr) (string, bool) {
+	obj := named.Obj()
+	if obj.Pkg() == nil || obj.Pkg() == w.Pkg {
+		return obj.Name(), true
+	}
+	for _, file := range w.Files {
+		if expr.Pos() < file.Pos() || expr.Pos() > file.End() {
+			continue
+		}
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path != obj.Pkg().Path() {
+				continue
+			}
+			switch {
+			case spec.Name == nil:
+				return obj.Pkg().Name() + "." + obj.Name(), true
+			case spec.Name.Name == ".":
+				return obj.Name(), true
+			case spec.Name.Name != "_":
+				return spec.Name.Name + "." + obj.Name(), true
+			}
+		}
+	}
+	return "", false
+}
+
+// SuggestedFixes return the mechanical fixes of diagnostic, e.g
+// Offsetof(Detail{}.Name) to Offsetof(Product{}.Name) when brick model Product has field Name,
+// OrderBy("Nmae") to OrderBy("Name"),
+// toyorm.Open("sqlit3", ...) to toyorm.Open("sqlite3", ...),
+// brick.OrderBy(...) to brick = brick.OrderBy(...)
+func (w *Walker) SuggestedFixes(d Diagnostic) []analysis.SuggestedFix {
+	switch e := d.(type) {
+	case ErrDifferentStruct:
+		sel, ok := e.Target.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		lit, ok := sel.X.(*ast.CompositeLit)
+		// the elements of literal may not exist in source type
+		if !ok || lit.Type == nil || len(lit.Elts) != 0 {
+			return nil
+		}
+		if field, _, _ := types.LookupFieldOrMethod(e.Source, false, w.Pkg, sel.Sel.Name); field == nil {
+			return nil
+		} else if _, ok := field.(*types.Var); !ok {
+			return nil
+		}
+		name, ok := w.typeName(e.Source, lit)
+		if !ok {
+			return nil
+		}
+		return []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("use %s", name),
+			TextEdits: []analysis.TextEdit{
+				{Pos: lit.Type.Pos(), End: lit.Type.End(), NewText: []byte(name)},
+			},
+		}}
+	case ErrInvalidField:
+		lit, ok := e.Expr.(*ast.BasicLit)
+		if !ok {
+			return nil
+		}
+		name, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return nil
+		}
+		field, ok := w.closestField(name, e.Source)
+		if !ok {
+			return nil
+		}
+		return []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("use field %s", field),
+			TextEdits: []analysis.TextEdit{
+				{Pos: lit.Pos(), End: lit.End(), NewText: []byte(strconv.Quote(field))},
+			},
+		}}
+	case ErrDiscardedResult:
+		return e.suggestedFixes()
+	case ErrUnknownDriver:
+		lit, ok := e.Expr.(*ast.BasicLit)
+		// not sure which one is right
+		if !ok || len(e.Suggestions) == 0 ||
+			len(e.Suggestions) > 1 && nameDistance(e.Name, e.Suggestions[0]) == nameDistance(e.Name, e.Suggestions[1]) {
+			return nil
+		}
+		return []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("use driver %s", e.Suggestions[0]),
+			TextEdits: []analysis.TextEdit{
+				{Pos: lit.Pos(), End: lit.End(), NewText: []byte(strconv.Quote(e.Suggestions[0]))},
+			},
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, levenshtein("", "abc"), 3)
+	assert.Equal(t, levenshtein("Nmae", "Name"), 1)
+	assert.Equal(t, levenshtein("kitten", "sitting"), 3)
+	assert.Equal(t, levenshtein("Name", "Name"), 0)
+}
+
+func TestClosestNames(t *testing.T) {
+	candidates := []string{"Name", "Data", "ID", "CreatedAt"}
+	assert.Equal(t, closestNames("name", candidates), []string{"Name"})
+	assert.Equal(t, closestNames("CreateAt", candidates), []string{"CreatedAt"})
+	assert.Nil(t, closestNames("NotExist", candidates))
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	walk := walkTestFile(t, "testdata/fix.go")
+	var fixes []string
+	for _, d := range walk.Diagnostics() {
+		for _, fix := range walk.SuggestedFixes(d) {
+			for _, edit := range fix.TextEdits {
+				fixes = append(fixes, walk.FS.Position(edit.Pos).String()+" "+string(edit.NewText))
+			}
+		}
+	}
+	assert.Equal(t, fixes, []string{
+		"testdata/fix.go:30:52 Product",
+		"testdata/fix.go:34:36 \"Name\"",
+		"testdata/fix.go:35:36 \"Name\"",
+		"testdata/fix.go:36:36 \"cost\"",
+	})
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+const RuleAmbiguousBrick = "ambiguous-brick"
+
+// brick may carry different models on different paths
+type ErrAmbiguousBrick struct {
+	FileSet  *token.FileSet
+	Expr     ast.Expr
+	Contexts []TypesStructList
+}
+
+func (e ErrAmbiguousBrick) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Message())
+}
+
+func (e ErrAmbiguousBrick) Rule() string   { return RuleAmbiguousBrick }
+func (e ErrAmbiguousBrick) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrAmbiguousBrick) End() token.Pos { return e.Expr.End() }
+func (e ErrAmbiguousBrick) Message() string {
+	var models []string
+	for _, ctx := range e.Contexts {
+		models = append(models, ctx.String())
+	}
+	return fmt.Sprintf("brick may carry different models on different paths: %s", strings.Join(models, ", "))
+}
+
+// set brick context after assignment, nil context mean unknown
+func (w *Walker) setBrickContext(obj types.Object, ctx TypesStructList) {
+	delete(w.BrickAmbiguous, obj)
+	if len(ctx) == 0 {
+		delete(w.BrickIdentCache, obj)
+	} else {
+		w.BrickIdentCache[obj] = ctx
+	}
+}
+
+// set brick context or the ambiguous contexts
+func (w *Walker) setBrickContexts(obj types.Object, ctx TypesStructList, ctxList []TypesStructList) {
+	if len(ctxList) != 0 {
+		delete(w.BrickIdentCache, obj)
+		w.BrickAmbiguous[obj] = ctxList
+		return
+	}
+	w.setBrickContext(obj, ctx)
+}
+
+// copy brick context or the ambiguous contexts from other brick
+func (w *Walker) copyBrickContext(obj, from types.Object) {
+	ctx, ctxList := w.objContext(from)
+	w.setBrickContexts(obj, ctx, ctxList)
+}
+
+// get brick context of ident, report error when ident is ambiguous
+func (w *Walker) identContext(ident *ast.Ident) TypesStructList {
+	ctx, ctxList := w.objContext(w.Info.Uses[ident])
+	return w.checkAmbiguous(ident, ctx, ctxList)
+}
+
+// report error when the brick expression have different contexts
+func (w *Walker) checkAmbiguous(expr ast.Expr, ctx TypesStructList, ctxList []TypesStructList) TypesStructList {
+	if len(ctxList) != 0 {
+		w.CheckedExpr[expr] = struct{}{}
+		w.ErrorExpr[expr] = append(w.ErrorExpr[expr], ErrAmbiguousBrick{w.FS, expr, ctxList})
+		return nil
+	}
+	return ctx
+}
+
+// merge brick context of branches at join point, different context on branches become ambiguous,
+// the brick without context on some branches is unknown unless the known contexts are already different,
+// the method value different on branches is unknown too
+func (w *Walker) merge(branches ...*Walker) {
+	candidates := map[types.Object][]TypesStructList{}
+	// number of branches the brick has context
+	known := map[types.Object]int{}
+	var objs []types.Object
+	add := func(obj types.Object, ctx TypesStructList) {
+		if _, ok := candidates[obj]; ok == false {
+			objs = append(objs, obj)
+		}
+		for _, c := range candidates[obj] {
+			if c.Equal(ctx) {
+				return
+			}
+		}
+		candidates[obj] = append(candidates[obj], ctx)
+	}
+	for _, branch := range branches {
+		for obj, ctx := range branch.BrickIdentCache {
+			known[obj]++
+			add(obj, ctx)
+		}
+		for obj, ctxList := range branch.BrickAmbiguous {
+			known[obj]++
+			for _, ctx := range ctxList {
+				add(obj, ctx)
+			}
+		}
+	}
+	// method value is kept when all branches have the same one
+	methodValues := map[types.Object]MethodValue{}
+	for obj, mv := range branches[0].MethodValues {
+		same := true
+		for _, branch := range branches[1:] {
+			if other, ok := branch.MethodValues[obj]; !ok || other.Method != mv.Method || other.Expr != mv.Expr || !other.Ctx.Equal(mv.Ctx) {
+				same = false
+				break
+			}
+		}
+		if same {
+			methodValues[obj] = mv
+		}
+	}
+	w.MethodValues = methodValues
+	w.BrickIdentCache = map[types.Object]TypesStructList{}
+	w.BrickAmbiguous = map[types.Object][]TypesStructList{}
+	for _, obj := range objs {
+		ctxList := candidates[obj]
+		switch {
+		case len(ctxList) > 1:
+			w.BrickAmbiguous[obj] = ctxList
+		case known[obj] == len(branches):
+			w.BrickIdentCache[obj] = ctxList[0]
+		}
+	}
+}
+
+// walker use to compute the brick context after loop body, all check result are dropped
+func (w *Walker) dryCopy() *Walker {
+	nw := w.copy()
+	nw.BrickCallCache = map[*ast.CallExpr]TypesStructList{}
+	nw.ParamSites = map[*types.Var][]BrickSite{}
+	nw.ResultSites = map[*types.Var][]BrickSite{}
+	nw.FieldSites = map[*types.Var][]BrickSite{}
+	nw.ElemSites = map[types.Object][]ElemSite{}
+	nw.AllExpr = map[ast.Expr]struct{}{}
+	nw.CheckedExpr = map[ast.Expr]struct{}{}
+	nw.ErrorExpr = map[ast.Expr][]error{}
+	nw.WarningExpr = map[ast.Expr][]error{}
+	return nw
+}
+
+func (w *Walker) walkNode(nodes ...ast.Node) {
+	for _, node := range nodes {
+		// ast.Walk not accept nil node
+		if node != nil && reflect.ValueOf(node).IsNil() == false {
+			ast.Walk(w, node)
+		}
+	}
+}
+
+// if cond {...} else {...}
+func (w *Walker) walkIf(stmt *ast.IfStmt) {
+	w.walkNode(stmt.Init, stmt.Cond)
+	thenW, elseW := w.copy(), w.copy()
+	thenW.walkNode(stmt.Body)
+	if stmt.Else != nil {
+		elseW.walkNode(stmt.Else)
+	}
+	var branches []*Walker
+	if terminates(stmt.Body) == false {
+		branches = append(branches, thenW)
+	}
+	if block, ok := stmt.Else.(*ast.BlockStmt); ok == false || terminates(block) == false {
+		branches = append(branches, elseW)
+	}
+	if len(branches) != 0 {
+		w.merge(branches...)
+	}
+}
+
+// switch/type switch/select statement
+func (w *Walker) walkSwitch(init ast.Stmt, tag ast.Node, body *ast.BlockStmt) {
+	w.walkNode(init, tag)
+	var branches []*Walker
+	hasDefault := false
+	// the clause end with fallthrough, its context flow into next clause
+	var fall *Walker
+	// break leave the switch only
+	var breaks []*Walker
+	for _, stmt := range body.List {
+		cw := w.copy()
+		cw.Breaks = &breaks
+		var list []ast.Stmt
+		switch clause := stmt.(type) {
+		case *ast.CaseClause:
+			hasDefault = hasDefault || clause.List == nil
+			for _, expr := range clause.List {
+				cw.walkNode(expr)
+			}
+			list = clause.Body
+		case *ast.CommClause:
+			hasDefault = hasDefault || clause.Comm == nil
+			cw.walkNode(clause.Comm)
+			list = clause.Body
+		}
+		if fall != nil {
+			cw.merge(cw, fall)
+			fall = nil
+		}
+		for _, s := range list {
+			cw.walkNode(s)
+		}
+		branch := lastBranch(list)
+		switch {
+		case branch != nil && branch.Tok == token.FALLTHROUGH:
+			fall = cw
+		case terminates(&ast.BlockStmt{List: list}) == false:
+			branches = append(branches, cw)
+		}
+	}
+	branches = append(branches, breaks...)
+	// no case matched
+	if hasDefault == false {
+		branches = append(branches, w.copy())
+	}
+	if len(branches) != 0 {
+		w.merge(branches...)
+	}
+}
+
+// for/range loop, body may run zero or more times,
+// the contexts at continue flow into next loop and the contexts at break flow out of loop
+func (w *Walker) walkLoop(init ast.Stmt, cond ast.Node, post ast.Stmt, body *ast.BlockStmt, vars ...ast.Expr) {
+	w.walkNode(init, cond)
+	// compute the brick context at the beginning of second loop
+	dw := w.dryCopy()
+	var dryContinues []*Walker
+	dw.Breaks, dw.Continues = &[]*Walker{}, &dryContinues
+	for _, v := range vars {
+		dw.walkNode(v)
+	}
+	dw.walkNode(body, post)
+	entry := w.copy()
+	entry.merge(append([]*Walker{w, dw}, dryContinues...)...)
+
+	var breaks, continues []*Walker
+	bw := entry.copy()
+	bw.Breaks, bw.Continues = &breaks, &continues
+	for _, v := range vars {
+		bw.walkNode(v)
+	}
+	bw.walkNode(body, post)
+	exits := []*Walker{entry}
+	if terminates(body) == false {
+		exits = append(exits, bw)
+	}
+	exits = append(exits, continues...)
+	w.merge(append(exits, breaks...)...)
+}
+
+// record the context at unlabeled break/continue
+func (w *Walker) recordBranch(stmt *ast.BranchStmt) {
+	if stmt.Label != nil {
+		return
+	}
+	switch {
+	case stmt.Tok == token.BREAK && w.Breaks != nil:
+		*w.Breaks = append(*w.Breaks, w.copy())
+	case stmt.Tok == token.CONTINUE && w.Continues != nil:
+		*w.Continues = append(*w.Continues, w.copy())
+	}
+}
+
+// the branch statement at the end of statements
+func lastBranch(list []ast.Stmt) *ast.BranchStmt {
+	if len(list) == 0 {
+		return nil
+	}
+	switch x := list[len(list)-1].(type) {
+	case *ast.BranchStmt:
+		return x
+	case *ast.BlockStmt:
+		return lastBranch(x.List)
+	}
+	return nil
+}
+
+// is the last statement in block return/panic/break/continue/goto,
+// fallthrough don't leave the switch, it is handled by walkSwitch
+func terminates(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+	switch x := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		switch x.Tok {
+		case token.BREAK, token.CONTINUE, token.GOTO:
+			return true
+		}
+	case *ast.BlockStmt:
+		return terminates(x)
+	case *ast.ExprStmt:
+		if call, ok := x.X.(*ast.CallExpr); ok {
+			if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "panic" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+const (
+	ignoreDirective = "toy-doctor:ignore"
+
+	RuleUnusedIgnore = "unused-ignore"
+)
+
+// IgnoreDirective is a //toy-doctor:ignore [rule...] reason comment,
+// it suppress the errors between Start and End, empty Rules means all rules
+type IgnoreDirective struct {
+	Comment *ast.Comment
+	Rules   []string
+	Reason  string
+	Start   token.Pos
+	End     token.Pos
+	Used    bool
+}
+
+func (d *IgnoreDirective) match(diag Diagnostic) bool {
+	if diag.Pos() < d.Start || diag.Pos() > d.End {
+		return false
+	}
+	if len(d.Rules) == 0 {
+		return true
+	}
+	for _, rule := range d.Rules {
+		if rule == diag.Rule() {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoredError is the error suppressed by directive
+type IgnoredError struct {
+	Diagnostic
+	Directive *IgnoreDirective
+}
+
+// the directive suppress nothing, it should be removed
+type ErrUnusedIgnore struct {
+	FileSet   *token.FileSet
+	Directive *IgnoreDirective
+}
+
+func (e ErrUnusedIgnore) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Pos()), e.Message())
+}
+
+func (e ErrUnusedIgnore) Rule() string    { return RuleUnusedIgnore }
+func (e ErrUnusedIgnore) Pos() token.Pos  { return e.Directive.Comment.Pos() }
+func (e ErrUnusedIgnore) End() token.Pos  { return e.Directive.Comment.End() }
+func (e ErrUnusedIgnore) Message() string { return "toy-doctor:ignore directive suppress nothing" }
+
+// parse the directive text, the leading words are rule ids and the rest is reason
+// e.g //toy-doctor:ignore different-struct invalid-field generic helper
+// the generic "error" rule is too common in reason to be a rule word, use the directive without rules for it
+// gofmt add a space after // in doc comment, so "// toy-doctor:ignore" is accepted in doc comment only
+func parseIgnoreDirective(text string, doc bool) (rules []string, reason string, ok bool) {
+	if !strings.HasPrefix(text, "//") {
+		return nil, "", false
+	}
+	text = text[2:]
+	if doc {
+		text = strings.TrimLeft(text, " ")
+	}
+	if !strings.HasPrefix(text, ignoreDirective) {
+		return nil, "", false
+	}
+	rest := text[len(ignoreDirective):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, "", false
+	}
+	words := strings.Fields(rest)
+	i := 0
+	for ; i < len(words); i++ {
+		if index, _ := ruleInfo(words[i]); index == -1 || words[i] == RuleError {
+			break
+		}
+		rules = append(rules, words[i])
+	}
+	return rules, strings.Join(words[i:], " "), true
+}
+
+// get the outermost statement/declaration/field start at line
+func lineNode(fs *token.FileSet, file *ast.File, line int) ast.Node {
+	var found ast.Node
+	ast.Inspect(file, func(node ast.Node) bool {
+		if found != nil || node == nil {
+			return false
+		}
+		if fs.Position(node.End()).Line < line || fs.Position(node.Pos()).Line > line {
+			return false
+		}
+		switch node.(type) {
+		case ast.Stmt, ast.Decl, ast.Spec, *ast.Field:
+			if fs.Position(node.Pos()).Line == line {
+				found = node
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// collect directives in file, the directive scope is
+// the function if it is in function doc,
+// the statement start at the same line if it is a trailing comment,
+// otherwise the statement start at next line of comment group
+func (w *Walker) fileIgnores(file *ast.File) []*IgnoreDirective {
+	funcDoc := map[*ast.CommentGroup]*ast.FuncDecl{}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc != nil {
+			funcDoc[fn.Doc] = fn
+		}
+	}
+	docs := map[*ast.CommentGroup]bool{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.FuncDecl:
+			docs[x.Doc] = true
+		case *ast.GenDecl:
+			docs[x.Doc] = true
+		case *ast.TypeSpec:
+			docs[x.Doc] = true
+		case *ast.ValueSpec:
+			docs[x.Doc] = true
+		case *ast.Field:
+			docs[x.Doc] = true
+		}
+		return true
+	})
+	var directives []*IgnoreDirective
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			rules, reason, ok := parseIgnoreDirective(c.Text, docs[group])
+			if !ok {
+				continue
+			}
+			d := &IgnoreDirective{Comment: c, Rules: rules, Reason: reason}
+			line := w.FS.Position(c.Pos()).Line
+			var node ast.Node
+			if fn, ok := funcDoc[group]; ok {
+				node = fn
+			} else if n := lineNode(w.FS, file, line); n != nil && n.Pos() < c.Pos() {
+				node = n
+			} else {
+				node = lineNode(w.FS, file, w.FS.Position(group.End()).Line+1)
+			}
+			if node != nil {
+				d.Start, d.End = node.Pos(), node.End()
+			} else {
+				d.Start, d.End = c.Pos(), c.End()
+			}
+			directives = append(directives, d)
+		}
+	}
+	return directives
+}
+
+// move the errors and warnings suppressed by directives from ErrorExpr/WarningExpr to IgnoredExpr
+func (w *Walker) applyIgnores() {
+	w.Ignores = nil
+	w.IgnoredExpr = map[ast.Expr][]IgnoredError{}
+	for _, file := range w.Files {
+		w.Ignores = append(w.Ignores, w.fileIgnores(file)...)
+	}
+	if len(w.Ignores) == 0 {
+		return
+	}
+	w.ignoreErrors(w.ErrorExpr)
+	w.ignoreErrors(w.WarningExpr)
+}
+
+func (w *Walker) ignoreErrors(exprErrors map[ast.Expr][]error) {
+	for expr, errs := range exprErrors {
+		var remain []error
+		for _, err := range errs {
+			diag := toDiagnostic(expr, err)
+			var directive *IgnoreDirective
+			for _, d := range w.Ignores {
+				if d.match(diag) {
+					directive = d
+					break
+				}
+			}
+			if directive != nil {
+				directive.Used = true
+				w.IgnoredExpr[expr] = append(w.IgnoredExpr[expr], IgnoredError{diag, directive})
+			} else {
+				remain = append(remain, err)
+			}
+		}
+		if len(remain) == 0 {
+			delete(exprErrors, expr)
+		} else {
+			exprErrors[expr] = remain
+		}
+	}
+}
+
+// IgnoredDiagnostics return all errors suppressed by directives sorted by position
+func (w *Walker) IgnoredDiagnostics() []IgnoredError {
+	var ignored []IgnoredError
+	for _, errs := range w.IgnoredExpr {
+		ignored = append(ignored, errs...)
+	}
+	sort.Slice(ignored, func(i, j int) bool {
+		return ignored[i].Pos() < ignored[j].Pos()
+	})
+	return ignored
+}
+
+// Warnings return the warnings found by walker sorted by position and the directives suppress nothing
+func (w *Walker) Warnings() []Diagnostic {
+	var warnings []Diagnostic
+	for expr, errs := range w.WarningExpr {
+		for _, err := range errs {
+			warnings = append(warnings, toDiagnostic(expr, err))
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Pos() < warnings[j].Pos()
+	})
+	for _, d := range w.Ignores {
+		if !d.Used {
+			warnings = append(warnings, ErrUnusedIgnore{w.FS, d})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseIgnoreDirective(t *testing.T) {
+	rules, reason, ok := parseIgnoreDirective("//toy-doctor:ignore different-struct invalid-field generic helper", false)
+	assert.True(t, ok)
+	assert.Equal(t, rules, []string{RuleDifferentStruct, RuleInvalidField})
+	assert.Equal(t, reason, "generic helper")
+
+	// "error" is part of reason, not the generic rule
+	rules, reason, ok = parseIgnoreDirective("//toy-doctor:ignore error in legacy helper", false)
+	assert.True(t, ok)
+	assert.Nil(t, rules)
+	assert.Equal(t, reason, "error in legacy helper")
+	rules, reason, ok = parseIgnoreDirective("//toy-doctor:ignore invalid-field error in legacy helper", false)
+	assert.True(t, ok)
+	assert.Equal(t, rules, []string{RuleInvalidField})
+	assert.Equal(t, reason, "error in legacy helper")
+
+	rules, reason, ok = parseIgnoreDirective("//toy-doctor:ignore", false)
+	assert.True(t, ok)
+	assert.Nil(t, rules)
+	assert.Equal(t, reason, "")
+
+	_, _, ok = parseIgnoreDirective("//toy-doctor:ignored", false)
+	assert.False(t, ok)
+	_, reason, ok = parseIgnoreDirective("// toy-doctor:ignore doc comment", true)
+	assert.True(t, ok)
+	assert.Equal(t, reason, "doc comment")
+	// the spaced form is a normal comment outside doc comment
+	_, _, ok = parseIgnoreDirective("// toy-doctor:ignore doc comment", false)
+	assert.False(t, ok)
+	_, _, ok = parseIgnoreDirective("/* toy-doctor:ignore */", false)
+	assert.False(t, ok)
+}
+
+func TestWalkIgnore(t *testing.T) {
+	walk := walkTestFile(t, "testdata/ignore.go")
+	diags := walk.Diagnostics()
+	if assert.Equal(t, len(diags), 2) {
+		assert.Equal(t, diags[0].Rule(), RuleInvalidField)
+		assert.Equal(t, walk.FS.Position(diags[0].Pos()).Line, 38)
+		// spaced directive isn't in doc comment
+		assert.Equal(t, diags[1].Rule(), RuleDifferentStruct)
+		assert.Equal(t, walk.FS.Position(diags[1].Pos()).Line, 46)
+	}
+	assert.Equal(t, len(walk.IgnoredDiagnostics()), 6)
+	warnings := walk.Warnings()
+	// rule not match and stale directive
+	if assert.Equal(t, len(warnings), 2) {
+		assert.Equal(t, warnings[0].Rule(), RuleUnusedIgnore)
+		assert.Equal(t, walk.FS.Position(warnings[0].Pos()).Line, 38)
+		assert.Equal(t, walk.FS.Position(warnings[1].Pos()).Line, 41)
+	}
+
+	walk.ShowIgnored = true
+	assert.True(t, strings.Contains(walk.Report(), "(ignored: generic helper)"))
+	// errors, ignored errors and warnings
+	assert.Equal(t, len(walk.JSONDiagnostics()), 10)
+}
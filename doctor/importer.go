@@ -0,0 +1,51 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// ToyormPath is the import path of toyorm
+const ToyormPath = "github.com/bigpigeon/toyorm"
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// importer use the types of package already imported by pkg
+func typesImporter(pkg *types.Package) types.Importer {
+	deps := map[string]*types.Package{}
+	var visit func(p *types.Package)
+	visit = func(p *types.Package) {
+		if _, ok := deps[p.Path()]; ok {
+			return
+		}
+		deps[p.Path()] = p
+		for _, imp := range p.Imports() {
+			visit(imp)
+		}
+	}
+	visit(pkg)
+	return importerFunc(func(path string) (*types.Package, error) {
+		if path == "unsafe" {
+			return types.Unsafe, nil
+		}
+		if p, ok := deps[path]; ok {
+			return p, nil
+		}
+		return nil, fmt.Errorf("package %s not found in dependencies of %s", path, pkg.Path())
+	})
+}
+
+// ImportsToyorm report whether pkg import toyorm directly or through its dependencies, e.g a wrapper package
+func ImportsToyorm(pkg *types.Package) bool {
+	return importsAny(pkg, []string{ToyormPath})
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+)
+
+// JSONVersion is the version of json report schema, it will be increased when field are changed or removed
+// version 2 add the warnings with severity
+const JSONVersion = 2
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ModelError is the error that compared with brick model
+type ModelError interface {
+	Diagnostic
+	Model() *types.Named
+}
+
+func (e ErrDifferentStruct) Model() *types.Named { return e.Source }
+func (e ErrInvalidField) Model() *types.Named    { return e.Source }
+func (e ErrDifferentRecord) Model() *types.Named { return e.Source }
+
+type JSONReport struct {
+	Version     int              `json:"version"`
+	Diagnostics []JSONDiagnostic `json:"diagnostics"`
+}
+
+type JSONPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type JSONModel struct {
+	Name string `json:"name"`
+	JSONPosition
+}
+
+type JSONDiagnostic struct {
+	JSONPosition
+	EndLine   int    `json:"end_line"`
+	EndColumn int    `json:"end_column"`
+	Rule      string `json:"rule"`
+	// error or warning, warning don't change the exit code
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// the model struct error compared against
+	Model *JSONModel `json:"model,omitempty"`
+	// source of brick chain the error belongs to
+	Chain string `json:"chain,omitempty"`
+	// suppressed by //toy-doctor:ignore, only output with -show-ignored
+	Ignored      bool   `json:"ignored,omitempty"`
+	IgnoreReason string `json:"ignore_reason,omitempty"`
+}
+
+func jsonPosition(position token.Position) JSONPosition {
+	return JSONPosition{position.Filename, position.Line, position.Column}
+}
+
+// JSONDiagnostics convert all errors and warnings to json diagnostics, the ignored errors are included when ShowIgnored
+func (w *Walker) JSONDiagnostics() []JSONDiagnostic {
+	chains := w.chainCalls()
+	var diags []JSONDiagnostic
+	for _, d := range w.Diagnostics() {
+		diags = append(diags, w.jsonDiagnostic(d, SeverityError, chains))
+	}
+	for _, d := range w.Warnings() {
+		diags = append(diags, w.jsonDiagnostic(d, SeverityWarning, chains))
+	}
+	if w.ShowIgnored {
+		for _, ignored := range w.IgnoredDiagnostics() {
+			jd := w.jsonDiagnostic(ignored.Diagnostic, SeverityError, chains)
+			jd.Ignored = true
+			jd.IgnoreReason = ignored.Directive.Reason
+			diags = append(diags, jd)
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].File != diags[j].File {
+			return diags[i].File < diags[j].File
+		}
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+	return diags
+}
+
+func (w *Walker) jsonDiagnostic(d Diagnostic, severity string, chains []ast.Node) JSONDiagnostic {
+	end := relPosition(w.FS, d.End())
+	jd := JSONDiagnostic{
+		JSONPosition: jsonPosition(relPosition(w.FS, d.Pos())),
+		EndLine:      end.Line,
+		EndColumn:    end.Column,
+		Rule:         d.Rule(),
+		Severity:     severity,
+		Message:      d.Message(),
+	}
+	if me, ok := d.(ModelError); ok {
+		model := me.Model().Obj()
+		jd.Model = &JSONModel{model.Name(), jsonPosition(relPosition(w.FS, model.Pos()))}
+	}
+	if chain := innermostNode(chains, d.Pos()); chain != nil {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, w.FS, chain); err == nil {
+			jd.Chain = buf.String()
+		}
+	}
+	return jd
+}
+
+// get the outermost calls of all brick chain
+// e.g toy.Model(&Product{}).Debug().OrderBy(...) is the chain of toy.Model(&Product{}).Debug()
+func (w *Walker) chainCalls() []ast.Node {
+	inner := map[*ast.CallExpr]bool{}
+	for call := range w.BrickCallCache {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			x := sel.X
+			for {
+				if paren, ok := x.(*ast.ParenExpr); ok {
+					x = paren.X
+				} else {
+					break
+				}
+			}
+			if xCall, ok := x.(*ast.CallExpr); ok {
+				inner[xCall] = true
+			}
+		}
+	}
+	var chains []ast.Node
+	for call := range w.BrickCallCache {
+		if inner[call] == false {
+			chains = append(chains, call)
+		}
+	}
+	return chains
+}
+
+// get the smallest node contain pos
+func innermostNode(nodes []ast.Node, pos token.Pos) ast.Node {
+	var result ast.Node
+	for _, node := range nodes {
+		if node.Pos() <= pos && pos < node.End() {
+			if result == nil || node.End()-node.Pos() < result.End()-result.Pos() {
+				result = node
+			}
+		}
+	}
+	return result
+}
+
+// WriteJSON write the diagnostics of all walkers as json report
+func WriteJSON(out io.Writer, walkers []*Walker) error {
+	report := JSONReport{Version: JSONVersion, Diagnostics: []JSONDiagnostic{}}
+	for _, w := range walkers {
+		report.Diagnostics = append(report.Diagnostics, w.JSONDiagnostics()...)
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(report)
+}
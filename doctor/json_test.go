@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	walk := walkTestFile(t, "testdata/struct_notmatch.go")
+	var buf bytes.Buffer
+	assert.Nil(t, WriteJSON(&buf, []*Walker{walk}))
+	t.Log(buf.String())
+
+	var report JSONReport
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, report.Version, JSONVersion)
+	assert.Equal(t, len(report.Diagnostics), len(walk.Diagnostics()))
+	for _, d := range report.Diagnostics {
+		assert.Equal(t, d.File, "testdata/struct_notmatch.go")
+		assert.NotEmpty(t, d.Rule)
+		assert.Equal(t, d.Severity, SeverityError)
+		assert.NotEmpty(t, d.Chain)
+	}
+	assert.Equal(t, report.Diagnostics[0].Rule, RuleUnknownDriver)
+	// _ = toy.Model(&Product{}).Debug().OrderBy("NotExistData", "NotExistTime")
+	d := report.Diagnostics[2]
+	assert.Equal(t, d.Rule, RuleInvalidField)
+	assert.Equal(t, d.Line, 47)
+	assert.Equal(t, d.Column, 44)
+	assert.Equal(t, d.EndColumn, 58)
+	assert.Equal(t, d.Model.Name, "Product")
+	assert.Equal(t, d.Model.Line, 26)
+	assert.Equal(t, d.Chain, `toy.Model(&Product{}).Debug().OrderBy("NotExistData", "NotExistTime")`)
+}
+
+func TestWriteJSONWarnings(t *testing.T) {
+	walk := walkTestFile(t, "testdata/nested.go")
+	var buf bytes.Buffer
+	assert.Nil(t, WriteJSON(&buf, []*Walker{walk}))
+
+	var report JSONReport
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &report))
+	var severities []string
+	for _, d := range report.Diagnostics {
+		severities = append(severities, fmt.Sprintf("%d %s %s", d.Line, d.Rule, d.Severity))
+	}
+	assert.Equal(t, severities, []string{
+		"33 unbalanced-pop error",
+		"34 unbalanced-pop error",
+		"36 nested-terminal warning",
+		"39 nested-terminal warning",
+	})
+}
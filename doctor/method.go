@@ -0,0 +1,69 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// MethodValue is the method value or method expression of ToyBrick that store in variable
+type MethodValue struct {
+	Method types.Object
+	// method expression receive brick as the first argument
+	Expr bool
+	// brick context when method value was evaluated
+	Ctx TypesStructList
+}
+
+// is the method Toy.Model or ToyBrick chain method
+func (w *Walker) isToyMethod(obj types.Object) bool {
+	return w.IsBrickChain(obj) || w.IsPushMethod(obj) || w.IsPopMethod(obj) || w.ToyModel.String() == obj.String()
+}
+
+// for the declarations
+// method := brick.Limit
+// method := (*toyorm.ToyBrick).OrderBy
+// method = other ............................ copy the method value of other
+// method = strings.ToUpper .................. not a method value any more
+func (w *Walker) cacheMethodValue(lhIdent *ast.Ident, expr ast.Expr) {
+	lhObj := w.Info.Defs[lhIdent]
+	if lhObj == nil {
+		lhObj = w.Info.Uses[lhIdent]
+	}
+	if lhObj == nil {
+		return
+	}
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if method, ok := w.MethodValues[w.Info.Uses[x]]; ok {
+			w.MethodValues[lhObj] = method
+			return
+		}
+	case *ast.SelectorExpr:
+		selection := w.Info.Selections[x]
+		if selection == nil || w.isToyMethod(selection.Obj()) == false {
+			break
+		}
+		switch selection.Kind() {
+		case types.MethodVal:
+			var ctx TypesStructList
+			if selCall, ok := x.X.(*ast.CallExpr); ok {
+				ctx = w.checkCallExpr(selCall)
+			} else if selIdent := getIdent(x.X); selIdent != nil {
+				ctx = w.identContext(selIdent)
+			}
+			w.MethodValues[lhObj] = MethodValue{Method: selection.Obj(), Ctx: ctx}
+			return
+		case types.MethodExpr:
+			w.MethodValues[lhObj] = MethodValue{Method: selection.Obj(), Expr: true}
+			return
+		}
+	}
+	// reassigned with other value
+	delete(w.MethodValues, lhObj)
+}
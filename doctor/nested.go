@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+const (
+	RuleUnbalancedPop  = "unbalanced-pop"
+	RuleNestedTerminal = "nested-terminal"
+)
+
+// Enter/Swap called when brick is already at the root model
+type ErrUnbalancedPop struct {
+	FileSet *token.FileSet
+	Call    *ast.CallExpr
+	Method  types.Object
+	Root    *types.Named
+}
+
+func (e ErrUnbalancedPop) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Call.Pos()), e.Message())
+}
+
+func (e ErrUnbalancedPop) Rule() string   { return RuleUnbalancedPop }
+func (e ErrUnbalancedPop) Pos() token.Pos { return e.Call.Pos() }
+func (e ErrUnbalancedPop) End() token.Pos { return e.Call.End() }
+func (e ErrUnbalancedPop) Message() string {
+	return fmt.Sprintf("%s pop past the root model %s, no Preload/Join to leave", e.Method.Name(), e.Root.Obj().Name())
+}
+
+// terminal operation run while the brick still in Preload/Join context, it is a warning
+type ErrNestedTerminal struct {
+	FileSet *token.FileSet
+	Call    *ast.CallExpr
+	Method  types.Object
+	Ctx     TypesStructList
+}
+
+func (e ErrNestedTerminal) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Call.Pos()), e.Message())
+}
+
+func (e ErrNestedTerminal) Rule() string   { return RuleNestedTerminal }
+func (e ErrNestedTerminal) Pos() token.Pos { return e.Call.Pos() }
+func (e ErrNestedTerminal) End() token.Pos { return e.Call.End() }
+func (e ErrNestedTerminal) Message() string {
+	return fmt.Sprintf("%s run inside the nested context of %s, use Enter()/Swap() back to %s first",
+		e.Method.Name(), e.Ctx[len(e.Ctx)-1].Obj().Name(), e.Ctx[0].Obj().Name())
+}
+
+// for the declarations
+// toy.Model(&Product{}).Preload(Offsetof(Product{}.Detail)).Enter() ....... ok, back to Product
+// toy.Model(&Product{}).Enter() ............................................ error, Product is root
+func (w *Walker) checkPop(call *ast.CallExpr, method types.Object, ctx TypesStructList) TypesStructList {
+	switch {
+	case len(ctx) > 1:
+		// enter and swap haven't args
+		return ctx[:len(ctx)-1]
+	case len(ctx) == 1:
+		w.CheckedExpr[call] = struct{}{}
+		w.ErrorExpr[call] = append(w.ErrorExpr[call], ErrUnbalancedPop{w.FS, call, method, ctx[0]})
+	}
+	return ctx
+}
+
+// ToyBrick method return error is a terminal operation, e.g Find/Insert/Count
+func (w *Walker) isTerminalMethod(obj types.Object) bool {
+	method, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sign := method.Type().(*types.Signature)
+	if sign.Recv() == nil || !w.isBrickType(sign.Recv().Type()) || sign.Results().Len() == 0 {
+		return false
+	}
+	last := sign.Results().At(sign.Results().Len() - 1).Type()
+	return types.Identical(last, types.Universe.Lookup("error").Type())
+}
+
+// for the declarations
+// toy.Model(&Product{}).Preload(Offsetof(Product{}.Detail)).Find(&products) ....... warning, still in Detail
+func (w *Walker) checkNested(call *ast.CallExpr, method types.Object, ctx TypesStructList) {
+	if len(ctx) <= 1 || !w.isTerminalMethod(method) {
+		return
+	}
+	w.WarningExpr[call] = append(w.WarningExpr[call], ErrNestedTerminal{w.FS, call, method, ctx})
+}
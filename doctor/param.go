@@ -0,0 +1,168 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+const RuleParamConflict = "param-conflict"
+
+// BrickSite is a *ToyBrick expression that pass to function parameter or return from function
+type BrickSite struct {
+	Expr ast.Expr
+	Ctx  TypesStructList
+}
+
+// function parameter receive bricks with different model from call sites
+type ErrParamConflict struct {
+	FileSet *token.FileSet
+	Param   *ast.Ident
+	Sites   []BrickSite
+}
+
+func (e ErrParamConflict) Error() string {
+	var sites []string
+	for _, site := range e.Sites {
+		sites = append(sites, fmt.Sprintf("%s(%s)", site.Ctx, relPosition(e.FileSet, site.Expr.Pos())))
+	}
+	return fmt.Sprintf("%s brick parameter %s receive different models from call sites: %s",
+		relPosition(e.FileSet, e.Param.Pos()), e.Param.Name, strings.Join(sites, ", "))
+}
+
+func (e ErrParamConflict) Rule() string   { return RuleParamConflict }
+func (e ErrParamConflict) Pos() token.Pos { return e.Param.Pos() }
+func (e ErrParamConflict) End() token.Pos { return e.Param.End() }
+func (e ErrParamConflict) Message() string {
+	var models []string
+	for _, site := range e.Sites {
+		models = append(models, site.Ctx.String())
+	}
+	return fmt.Sprintf("brick parameter %s receive different models from call sites: %s", e.Param.Name, strings.Join(models, ", "))
+}
+
+// is the type *toyorm.ToyBrick
+func (w *Walker) isBrickType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	return t.String() == w.ToyModel.Type().(*types.Signature).Results().At(0).Type().String()
+}
+
+// get the model context of expression that value is *ToyBrick
+func (w *Walker) exprContext(expr ast.Expr) TypesStructList {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return w.exprContext(x.X)
+	case *ast.CallExpr:
+		return w.checkCallExpr(x)
+	case *ast.IndexExpr:
+		ctx, _ := w.elemContext(x)
+		return ctx
+	default:
+		if ident := getIdent(expr); ident != nil {
+			ctx, _ := w.objContext(w.Info.Uses[ident])
+			return ctx
+		}
+	}
+	return nil
+}
+
+// the function declared in this package that call expression invoke
+func (w *Walker) calledFunc(call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch x := call.Fun.(type) {
+	case *ast.Ident:
+		ident = x
+	case *ast.SelectorExpr:
+		ident = x.Sel
+	}
+	if ident == nil {
+		return nil
+	}
+	if fn, ok := w.Info.Uses[ident].(*types.Func); ok && fn.Pkg() == w.Pkg {
+		return fn
+	}
+	return nil
+}
+
+// record all *ToyBrick args those pass to the function declared in this package
+func (w *Walker) recordParamSites(call *ast.CallExpr) {
+	fn := w.calledFunc(call)
+	if fn == nil {
+		return
+	}
+	sign := fn.Type().(*types.Signature)
+	for i := 0; i < sign.Params().Len() && i < len(call.Args); i++ {
+		param := sign.Params().At(i)
+		// variadic brick params are ignored
+		if sign.Variadic() && i == sign.Params().Len()-1 {
+			break
+		}
+		if w.isBrickType(param.Type()) == false {
+			continue
+		}
+		if ctx := w.exprContext(call.Args[i]); len(ctx) != 0 {
+			w.ParamSites[param] = append(w.ParamSites[param], BrickSite{call.Args[i], ctx})
+		}
+	}
+}
+
+// seed the parameter brick context with the call sites collected by previous walk
+func (w *Walker) cacheParamBrick(decl *ast.FuncDecl) {
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			param, ok := w.Info.Defs[name].(*types.Var)
+			if ok == false || w.isBrickType(param.Type()) == false {
+				continue
+			}
+			sites := w.PrevParamSites[param]
+			if len(sites) == 0 {
+				continue
+			}
+			if ctx, ok := sitesContext(sites); ok {
+				w.BrickIdentCache[param] = ctx
+			} else {
+				w.ErrorExpr[name] = append(w.ErrorExpr[name], ErrParamConflict{w.FS, name, sites})
+				w.CheckedExpr[name] = struct{}{}
+			}
+		}
+	}
+}
+
+// get the context all sites agree with
+func sitesContext(sites []BrickSite) (TypesStructList, bool) {
+	ctx := sites[0].Ctx
+	for _, site := range sites[1:] {
+		if site.Ctx.Equal(ctx) == false {
+			return nil, false
+		}
+	}
+	return ctx, true
+}
+
+// is parameter or result context changed between two walk
+func sitesChanged(prev, current map[*types.Var][]BrickSite) bool {
+	if len(prev) != len(current) {
+		return true
+	}
+	for param, sites := range current {
+		if len(prev[param]) == 0 {
+			return true
+		}
+		prevCtx, prevOk := sitesContext(prev[param])
+		ctx, ok := sitesContext(sites)
+		if prevOk != ok || prevCtx.Equal(ctx) == false {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+const RuleDifferentRecord = "different-record"
+
+// record type of Find/Insert/Save... not match brick model
+type ErrDifferentRecord struct {
+	FileSet *token.FileSet
+	Source  *types.Named
+	Expr    ast.Expr
+	Type    types.Type
+}
+
+func (e ErrDifferentRecord) Error() string {
+	return fmt.Sprintf("%s record type %s must same as %s", relPosition(e.FileSet, e.Expr.Pos()), e.typeString(), relPosition(e.FileSet, e.Source.Obj().Pos()))
+}
+
+func (e ErrDifferentRecord) Rule() string   { return RuleDifferentRecord }
+func (e ErrDifferentRecord) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrDifferentRecord) End() token.Pos { return e.Expr.End() }
+func (e ErrDifferentRecord) Message() string {
+	return fmt.Sprintf("record type %s must same as %s", e.typeString(), e.Source.Obj().Name())
+}
+
+func (e ErrDifferentRecord) typeString() string {
+	return types.TypeString(e.Type, types.RelativeTo(e.Source.Obj().Pkg()))
+}
+
+// cache ToyBrick methods those operate records, e.g Find(v interface{}) (*Result, error)
+func (w *Walker) cacheRecordMethods() {
+	brickType := w.ToyModel.Type().(*types.Signature).Results().At(0).Type()
+	mset := types.NewMethodSet(brickType)
+	for i := 0; i < mset.Len(); i++ {
+		method, ok := mset.At(i).Obj().(*types.Func)
+		if ok == false {
+			continue
+		}
+		sign := method.Type().(*types.Signature)
+		if sign.Params().Len() != 1 || sign.Results().Len() != 2 {
+			continue
+		}
+		if iface, ok := sign.Params().At(0).Type().Underlying().(*types.Interface); ok && iface.Empty() {
+			w.ToyRecordMethod[method.String()] = struct{}{}
+		}
+	}
+}
+
+func (w *Walker) IsRecordMethod(obj types.Object) bool {
+	_, ok := w.ToyRecordMethod[obj.String()]
+	return ok
+}
+
+// check record type of Find/Insert/Save... must be *T, []T, []*T, *[]T or map, T is brick model
+// e.g
+// toy.Model(&Product{}).Find(&products)  ....... ok, products is []Product
+// toy.Model(&Product{}).Find(&details)  ........ error, details is []Detail
+func (w *Walker) checkRecord(call *ast.CallExpr, ctx TypesStructList) {
+	if len(ctx) == 0 || len(call.Args) == 0 {
+		return
+	}
+	mType := ctx[len(ctx)-1]
+	arg := call.Args[len(call.Args)-1]
+	w.markExpr(arg)
+	argType := w.Info.TypeOf(arg)
+	if argType == nil || isInterface(argType) {
+		return
+	}
+	w.CheckedExpr[arg] = struct{}{}
+	if recordCompatible(argType, mType) == false {
+		w.ErrorExpr[arg] = append(w.ErrorExpr[arg], ErrDifferentRecord{w.FS, mType, arg, argType})
+	}
+}
+
+func recordCompatible(t types.Type, mType *types.Named) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	switch x := t.Underlying().(type) {
+	case *types.Map:
+		return true
+	case *types.Slice:
+		t = x.Elem()
+	case *types.Array:
+		t = x.Elem()
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if isInterface(t) {
+		return true
+	}
+	if _, ok := t.Underlying().(*types.Map); ok {
+		return true
+	}
+	named, ok := t.(*types.Named)
+	return ok && types.Identical(named, mType)
+}
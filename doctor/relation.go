@@ -0,0 +1,336 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	RuleInvalidRelation = "invalid-relation"
+	RuleForeignKeyType  = "foreign-key-type"
+	RuleNoPrimaryKey    = "no-primary-key"
+)
+
+// the relation field toyorm need to preload a field, e.g Detail.ProductID for one to one
+type RelationField struct {
+	// model the field should be in
+	Model *types.Named
+	Name  string
+	Type  types.Type
+	// one to one, belong to or one to many
+	Kind string
+}
+
+func (f RelationField) String() string {
+	return fmt.Sprintf("%s.%s %s (%s)", f.Model.Obj().Name(), f.Name, types.TypeString(f.Type, types.RelativeTo(f.Model.Obj().Pkg())), f.Kind)
+}
+
+// preload field can't be resolved to any relation toyorm supported
+type ErrInvalidRelation struct {
+	FileSet *token.FileSet
+	Expr    ast.Expr
+	Model   *types.Named
+	Field   string
+	// one of the fields is needed
+	Expected []RelationField
+}
+
+func (e ErrInvalidRelation) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Message())
+}
+
+func (e ErrInvalidRelation) Rule() string   { return RuleInvalidRelation }
+func (e ErrInvalidRelation) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrInvalidRelation) End() token.Pos { return e.Expr.End() }
+func (e ErrInvalidRelation) Message() string {
+	var fields []string
+	for _, f := range e.Expected {
+		fields = append(fields, f.String())
+	}
+	return fmt.Sprintf("preload %s.%s have no relation, need %s", e.Model.Obj().Name(), e.Field, strings.Join(fields, " or "))
+}
+
+// relation field type not match the primary key it reference
+type ErrForeignKeyType struct {
+	FileSet  *token.FileSet
+	Expr     ast.Expr
+	Relation RelationField
+	Type     types.Type
+	// the model and primary key referenced
+	KeyModel *types.Named
+	Key      *types.Var
+}
+
+func (e ErrForeignKeyType) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Message())
+}
+
+func (e ErrForeignKeyType) Rule() string   { return RuleForeignKeyType }
+func (e ErrForeignKeyType) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrForeignKeyType) End() token.Pos { return e.Expr.End() }
+func (e ErrForeignKeyType) Message() string {
+	qualifier := types.RelativeTo(e.Relation.Model.Obj().Pkg())
+	return fmt.Sprintf("%s.%s type %s not match primary key %s.%s %s (%s)",
+		e.Relation.Model.Obj().Name(), e.Relation.Name, types.TypeString(e.Type, qualifier),
+		e.KeyModel.Obj().Name(), e.Key.Name(), types.TypeString(e.Key.Type(), qualifier), e.Relation.Kind)
+}
+
+// model haven't primary key, toyorm can't find or preload its records
+type ErrNoPrimaryKey struct {
+	FileSet *token.FileSet
+	Expr    ast.Expr
+	Model   *types.Named
+}
+
+func (e ErrNoPrimaryKey) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Expr.Pos()), e.Message())
+}
+
+func (e ErrNoPrimaryKey) Rule() string   { return RuleNoPrimaryKey }
+func (e ErrNoPrimaryKey) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrNoPrimaryKey) End() token.Pos { return e.Expr.End() }
+func (e ErrNoPrimaryKey) Message() string {
+	return fmt.Sprintf("model %s have no primary key, add ID field or primary key tag", e.Model.Obj().Name())
+}
+
+// the identifier of type declaration in analyzed files, nil when it is declared in other package
+func (w *Walker) typeDeclIdent(obj *types.TypeName) *ast.Ident {
+	for _, file := range w.Files {
+		if obj.Pos() < file.Pos() || obj.Pos() >= file.End() {
+			continue
+		}
+		var found *ast.Ident
+		ast.Inspect(file, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && ident.Pos() == obj.Pos() && w.Info.Defs[ident] == obj {
+				found = ident
+			}
+			return found == nil
+		})
+		return found
+	}
+	return nil
+}
+
+// check the model struct has primary key, the error is reported once at the model declaration,
+// the model declared in other package is reported at where it is used
+// e.g toy.Model(&Product{}) ....... error, Product haven't ID or primary key field
+func (w *Walker) checkPrimaryKey(expr ast.Expr, model *types.Named) {
+	if len(primaryKeys(modelFields(model.Underlying().(*types.Struct)))) != 0 {
+		return
+	}
+	w.CheckedExpr[expr] = struct{}{}
+	if ident := w.typeDeclIdent(model.Obj()); ident != nil {
+		expr = ident
+		// the error is reported at declaration
+		w.CheckedExpr[expr] = struct{}{}
+	}
+	for _, err := range w.ErrorExpr[expr] {
+		if e, ok := err.(ErrNoPrimaryKey); ok && e.Model == model {
+			return
+		}
+	}
+	w.ErrorExpr[expr] = append(w.ErrorExpr[expr], ErrNoPrimaryKey{w.FS, expr, model})
+}
+
+// the model field with toyorm tag, name is the alias if it has
+type modelField struct {
+	Var  *types.Var
+	Name string
+	Tag  map[string]string
+}
+
+// get all fields of model struct, the fields of embedded struct are expanded
+func modelFields(structType *types.Struct) []modelField {
+	var fields []modelField
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous() {
+			if subStructType, ok := field.Type().Underlying().(*types.Struct); ok {
+				fields = append(fields, modelFields(subStructType)...)
+			}
+			continue
+		}
+		f := modelField{Var: field, Name: field.Name(), Tag: map[string]string{}}
+		toyormTag, _ := reflect.StructTag(structType.Tag(i)).Lookup("toyorm")
+		for _, item := range parseToyormTag(toyormTag) {
+			f.Tag[item.Key] = item.Val
+		}
+		if alias := f.Tag["alias"]; alias != "" {
+			f.Name = alias
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func findModelField(fields []modelField, name string) *modelField {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// primary keys of model, they are the fields with primary key tag or the field named ID
+func primaryKeys(fields []modelField) []*modelField {
+	var keys []*modelField
+	for i := range fields {
+		if _, ok := fields[i].Tag["primary key"]; ok {
+			keys = append(keys, &fields[i])
+		}
+	}
+	if len(keys) == 0 {
+		if id := findModelField(fields, "ID"); id != nil {
+			keys = append(keys, id)
+		}
+	}
+	return keys
+}
+
+// the field of model selected by Preload arg, e.g Offsetof(Product{}.Detail) or "Detail"
+func (w *Walker) preloadField(expr ast.Expr, fields []modelField) *modelField {
+	switch x := expr.(type) {
+	case *ast.CallExpr:
+		if len(x.Args) == 0 {
+			return nil
+		}
+		sel, ok := x.Args[0].(*ast.SelectorExpr)
+		if !ok || w.Info.Selections[sel] == nil {
+			return nil
+		}
+		for i := range fields {
+			if fields[i].Var == w.Info.Selections[sel].Obj() {
+				return &fields[i]
+			}
+		}
+	case *ast.BasicLit:
+		name, err := strconv.Unquote(x.Value)
+		if err == nil {
+			return findModelField(fields, name)
+		}
+	}
+	return nil
+}
+
+// the single field tagged with foreign key, it is used when the relation field haven't conventional name
+func foreignKey(fields []modelField) *modelField {
+	var found *modelField
+	for i := range fields {
+		if _, ok := fields[i].Tag["foreign key"]; ok {
+			if found != nil {
+				return nil
+			}
+			found = &fields[i]
+		}
+	}
+	return found
+}
+
+// check toyorm can resolve the relation of Preload field,
+// struct or pointer field is belong to when model has field <Field><SubPrimaryKey> e.g Product.DetailID,
+// or one to one when sub model has field <Model><PrimaryKey> e.g Detail.ProductID,
+// slice field is one to many when sub model has field <Model><PrimaryKey>, otherwise it is many to many with middle table.
+// the names can be overridden by "belong to", "one to one" and "one to many" tag of preload field,
+// and the sub model field with "foreign key" tag is the relation field of one to one and one to many
+func (w *Walker) checkRelation(expr ast.Expr, model, sub *types.Named) {
+	fields := modelFields(model.Underlying().(*types.Struct))
+	subFields := modelFields(sub.Underlying().(*types.Struct))
+	field := w.preloadField(expr, fields)
+	if field == nil {
+		return
+	}
+	// the model without primary key can't be checked
+	modelKeys, subKeys := primaryKeys(fields), primaryKeys(subFields)
+	w.checkPrimaryKey(expr, sub)
+	if len(modelKeys) != 1 || len(subKeys) != 1 {
+		return
+	}
+	modelKey, subKey := modelKeys[0], subKeys[0]
+	fieldType := field.Var.Type()
+	if ptr, ok := fieldType.(*types.Pointer); ok {
+		fieldType = ptr.Elem()
+	}
+	_, isSlice := fieldType.Underlying().(*types.Slice)
+
+	// the relation field in sub model
+	subRelation := func(kind string) (RelationField, *modelField) {
+		expected := RelationField{sub, model.Obj().Name() + modelKey.Var.Name(), modelKey.Var.Type(), kind}
+		if name, ok := field.Tag[kind]; ok {
+			expected.Name = name
+			return expected, findModelField(subFields, name)
+		}
+		if found := findModelField(subFields, expected.Name); found != nil {
+			return expected, found
+		}
+		return expected, foreignKey(subFields)
+	}
+	var expected []RelationField
+	if isSlice {
+		oneToMany, found := subRelation("one to many")
+		if found != nil {
+			w.checkForeignKey(expr, oneToMany, found, model, modelKey)
+			return
+		}
+		// many to many when the one to many field isn't specified
+		if _, specified := field.Tag["one to many"]; !specified {
+			return
+		}
+		expected = append(expected, oneToMany)
+	} else {
+		// the specified relation is the only one can be used
+		if _, ok := field.Tag["one to one"]; !ok {
+			belongTo := RelationField{model, field.Var.Name() + subKey.Var.Name(), subKey.Var.Type(), "belong to"}
+			if name, ok := field.Tag["belong to"]; ok {
+				belongTo.Name = name
+			}
+			if found := findModelField(fields, belongTo.Name); found != nil {
+				w.checkForeignKey(expr, belongTo, found, sub, subKey)
+				return
+			}
+			expected = append(expected, belongTo)
+		}
+		if _, ok := field.Tag["belong to"]; !ok {
+			oneToOne, found := subRelation("one to one")
+			if found != nil {
+				w.checkForeignKey(expr, oneToOne, found, model, modelKey)
+				return
+			}
+			expected = append([]RelationField{oneToOne}, expected...)
+		}
+	}
+	w.ErrorExpr[expr] = append(w.ErrorExpr[expr], ErrInvalidRelation{w.FS, expr, model, field.Var.Name(), expected})
+}
+
+// foreign key type must be the primary key type, pointer for null value or the named type with same underlying type,
+// convertible type isn't enough, toyorm match the preloaded records by key value in map[interface{}],
+// int(1) and uint32(1) are different keys
+// e.g ProductID *uint32 is ok for ID uint32, ProductID int is not
+func foreignKeyCompatible(fk, pk types.Type) bool {
+	if ptr, ok := fk.(*types.Pointer); ok {
+		fk = ptr.Elem()
+	}
+	if ptr, ok := pk.(*types.Pointer); ok {
+		pk = ptr.Elem()
+	}
+	return types.AssignableTo(fk, pk) || types.Identical(fk.Underlying(), pk.Underlying())
+}
+
+func (w *Walker) checkForeignKey(expr ast.Expr, relation RelationField, found *modelField, keyModel *types.Named, key *modelField) {
+	if foreignKeyCompatible(found.Var.Type(), key.Var.Type()) {
+		return
+	}
+	relation.Name = found.Name
+	w.ErrorExpr[expr] = append(w.ErrorExpr[expr], ErrForeignKeyType{w.FS, expr, relation, found.Var.Type(), keyModel, key.Var})
+}
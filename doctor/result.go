@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// record the *ToyBrick results of current function
+// e.g
+// return toy.Model(&Product{}).Preload(Offsetof(Product{}.Detail))  ........ result context is Product->Detail
+func (w *Walker) recordResultSites(stmt *ast.ReturnStmt) {
+	if w.Func == nil {
+		return
+	}
+	results := w.Func.Type().(*types.Signature).Results()
+	// bare return or return a multiple value call are ignored
+	if len(stmt.Results) != results.Len() {
+		return
+	}
+	for i, expr := range stmt.Results {
+		result := results.At(i)
+		if w.isBrickType(result.Type()) == false {
+			continue
+		}
+		if ctx := w.exprContext(expr); len(ctx) != 0 {
+			w.ResultSites[result] = append(w.ResultSites[result], BrickSite{expr, ctx})
+		}
+	}
+}
+
+// get the i-th result context of function declared in this package, the result was collected by previous walk
+func (w *Walker) resultContext(call *ast.CallExpr, i int) TypesStructList {
+	fn := w.calledFunc(call)
+	if fn == nil {
+		return nil
+	}
+	results := fn.Type().(*types.Signature).Results()
+	if i >= results.Len() {
+		return nil
+	}
+	sites := w.PrevResultSites[results.At(i)]
+	if len(sites) == 0 {
+		return nil
+	}
+	// results with different model are unknown
+	if ctx, ok := sitesContext(sites); ok {
+		return ctx
+	}
+	return nil
+}
+
+// for the declarations
+// brick, err := productQuery(toy)
+func (w *Walker) cacheTupleBrickIdent(lhs []ast.Expr, call *ast.CallExpr) {
+	for i, expr := range lhs {
+		lhIdent := getIdent(expr)
+		if lhIdent == nil {
+			continue
+		}
+		lhObj := w.Info.Defs[lhIdent]
+		if lhObj == nil {
+			lhObj = w.Info.Uses[lhIdent]
+		}
+		if lhObj == nil || w.isBrickType(lhObj.Type()) == false {
+			continue
+		}
+		w.setBrickContext(lhObj, w.resultContext(call, i))
+	}
+}
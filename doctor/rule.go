@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+// the error without rule, e.g struct have duplicate field
+const RuleError = "error"
+
+// RuleInfo describe a rule of diagnostic
+type RuleInfo struct {
+	ID          string
+	Description string
+	Help        string
+}
+
+// Rules is all rules toy-doctor reported, new rule must be added here
+var Rules = []RuleInfo{
+	{
+		ID:          RuleDifferentStruct,
+		Description: "Field selection use a struct different from brick model",
+		Help:        "unsafe.Offsetof(T{}.Field) in brick chain must use the current model of brick, e.g toy.Model(&Product{}).OrderBy(Offsetof(Product{}.Name)). After Preload the model is the preloaded struct until Enter() is called.",
+	},
+	{
+		ID:          RuleInvalidField,
+		Description: "Field name not found in brick model",
+		Help:        "string field selection must be a field name or the alias in toyorm tag of current model.",
+	},
+	{
+		ID:          RuleInvalidStructField,
+		Description: "Preload/Join field is not a struct field",
+		Help:        "Preload and Join need a field with struct, pointer of struct or slice of struct type.",
+	},
+	{
+		ID:          RuleParamConflict,
+		Description: "Brick parameter receive different models from call sites",
+		Help:        "all call sites of a function should pass brick with same model to the *toyorm.ToyBrick parameter, otherwise the function can't be checked.",
+	},
+	{
+		ID:          RuleAmbiguousBrick,
+		Description: "Brick may carry different models on different paths",
+		Help:        "the brick is assigned with different models in branches or loop, use different variables for different models.",
+	},
+	{
+		ID:          RuleInvalidTag,
+		Description: "Invalid toyorm struct tag",
+		Help:        "toyorm tag is key:value pairs separated by ';', key must be supported by toyorm and can't be duplicated, e.g `toyorm:\"primary key;auto_increment\"`.",
+	},
+	{
+		ID:          RuleInvalidValue,
+		Description: "Condition value not match the selected field",
+		Help:        "the value of Where/Condition must be compatible with the field type and IN/NOT IN need a slice value.",
+	},
+	{
+		ID:          RuleInvalidOperator,
+		Description: "Unknown condition operator or wrong number of values",
+		Help:        "the operator of Where/Condition must be a toyorm SearchExpr, e.g toyorm.ExprEqual or \"=\" in the same case, and number of values must match the operator, BETWEEN/NOT BETWEEN need 2 values or a slice with 2 elements.",
+	},
+	{
+		ID:          RuleDifferentRecord,
+		Description: "Record type different from brick model",
+		Help:        "the record of Find/Insert/Save/Update/Delete must be *T, []T, []*T or map, T is the current model of brick.",
+	},
+	{
+		ID:          RuleInvalidRelation,
+		Description: "Preload field have no relation toyorm can resolve",
+		Help:        "Preload struct field need belong to (Product.DetailID) or one to one (Detail.ProductID) relation field, slice field with one to many tag need the field in sub model, the field name can be changed by belong to/one to one/one to many tag of preload field, alias tag or foreign key tag of relation field.",
+	},
+	{
+		ID:          RuleForeignKeyType,
+		Description: "Relation field type not match the referenced primary key",
+		Help:        "the relation field of Preload must have the type of the primary key it reference, pointer for null value or named type with same underlying type, e.g Detail.ProductID uint32 for toyorm.ModelDefault ID.",
+	},
+	{
+		ID:          RuleNoPrimaryKey,
+		Description: "Model have no primary key",
+		Help:        "toyorm model need a primary key, add an ID field, a field with primary key tag or embed toyorm.ModelDefault.",
+	},
+	{
+		ID:          RuleUnknownDriver,
+		Description: "Unknown driver name of toyorm.Open",
+		Help:        "the driver name passed to toyorm.Open must be a known database/sql driver, e.g sqlite3, mysql or postgres, more drivers can be added with -drivers file.",
+	},
+	{
+		ID:          RuleMissingDriver,
+		Description: "Driver of toyorm.Open is not imported",
+		Help:        "the package register the driver must be imported by the package or its dependencies, e.g import _ \"github.com/mattn/go-sqlite3\" for sqlite3.",
+	},
+	{
+		ID:          RuleDiscardedResult,
+		Description: "Result of brick chain method is discarded",
+		Help:        "brick chain methods return a new brick and don't modify the receiver, assign the result, e.g brick = brick.OrderBy(...).",
+	},
+	{
+		ID:          RuleUnbalancedPop,
+		Description: "Enter/Swap pop past the root model",
+		Help:        "Enter() and Swap() return to the model before Preload/Join, they can't be called more times than Preload/Join in the same chain.",
+	},
+	{
+		ID:          RuleNestedTerminal,
+		Description: "Terminal operation run inside Preload/Join context",
+		Help:        "the brick is still the preloaded or joined model, call Enter()/Swap() back to the root model before Find/Insert/Count..., e.g toy.Model(&Product{}).Preload(Offsetof(Product{}.Detail)).Enter().Find(&products).",
+	},
+	{
+		ID:          RuleUnusedIgnore,
+		Description: "Ignore directive suppress nothing",
+		Help:        "the //toy-doctor:ignore directive no longer suppress any error, remove it.",
+	},
+	{
+		ID:          RuleError,
+		Description: "Model definition error",
+		Help:        "the model struct can't be used by toyorm, e.g it has duplicate field names.",
+	},
+}
+
+// get the rule info and index by id
+func ruleInfo(id string) (int, RuleInfo) {
+	for i, rule := range Rules {
+		if rule.ID == id {
+			return i, rule
+		}
+	}
+	return -1, RuleInfo{ID: id}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifText          `json:"shortDescription"`
+	FullDescription      sarifText          `json:"fullDescription"`
+	Help                 sarifText          `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifText            `json:"message,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifText       `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Suppressions     []sarifSuppress `json:"suppressions,omitempty"`
+}
+
+type sarifSuppress struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+func sarifPhysical(file string, region sarifRegion) sarifPhysicalLocation {
+	location := sarifArtifactLocation{URI: filepath.ToSlash(file)}
+	if filepath.IsAbs(file) == false {
+		location.URIBaseID = "%SRCROOT%"
+	}
+	return sarifPhysicalLocation{location, region}
+}
+
+// unused directive and terminal operation in nested context are only warnings
+func ruleLevel(id string) string {
+	switch id {
+	case RuleUnusedIgnore, RuleNestedTerminal:
+		return "warning"
+	}
+	return "error"
+}
+
+// WriteSARIF write the diagnostics of all walkers as SARIF 2.1.0 log,
+// the model struct error referenced is a related location
+func WriteSARIF(out io.Writer, walkers []*Walker) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "toy-doctor",
+			InformationURI: "https://github.com/bigpigeon/toy-doctor",
+		}},
+		Results: []sarifResult{},
+	}
+	for _, rule := range Rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifText{rule.Description},
+			FullDescription:      sarifText{rule.Description},
+			Help:                 sarifText{rule.Help},
+			DefaultConfiguration: sarifConfiguration{ruleLevel(rule.ID)},
+		})
+	}
+	for _, w := range walkers {
+		for _, d := range w.JSONDiagnostics() {
+			index, _ := ruleInfo(d.Rule)
+			result := sarifResult{
+				RuleID:    d.Rule,
+				RuleIndex: index,
+				Level:     d.Severity,
+				Message:   sarifText{d.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysical(d.File, sarifRegion{d.Line, d.Column, d.EndLine, d.EndColumn}),
+				}},
+			}
+			if d.Model != nil {
+				result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+					ID:               1,
+					PhysicalLocation: sarifPhysical(d.Model.File, sarifRegion{StartLine: d.Model.Line, StartColumn: d.Model.Column}),
+					Message:          &sarifText{"model " + d.Model.Name},
+				})
+			}
+			if d.Ignored {
+				result.Suppressions = []sarifSuppress{{"inSource", d.IgnoreReason}}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	log := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	walk := walkTestFile(t, "testdata/struct_notmatch.go")
+	var buf bytes.Buffer
+	assert.Nil(t, WriteSARIF(&buf, []*Walker{walk}))
+	t.Log(buf.String())
+
+	var log sarifLog
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, log.Version, "2.1.0")
+	assert.Equal(t, len(log.Runs), 1)
+	run := log.Runs[0]
+	assert.Equal(t, len(run.Tool.Driver.Rules), len(Rules))
+	for _, rule := range run.Tool.Driver.Rules {
+		assert.NotEmpty(t, rule.ShortDescription.Text)
+		assert.NotEmpty(t, rule.Help.Text)
+	}
+	assert.Equal(t, len(run.Results), len(walk.Diagnostics()))
+	for _, result := range run.Results {
+		assert.Equal(t, run.Tool.Driver.Rules[result.RuleIndex].ID, result.RuleID)
+	}
+	assert.Equal(t, run.Results[0].RuleID, RuleUnknownDriver)
+	// _ = toy.Model(&Product{}).Debug().OrderBy("NotExistData", "NotExistTime")
+	result := run.Results[2]
+	assert.Equal(t, result.RuleID, RuleInvalidField)
+	location := result.Locations[0].PhysicalLocation
+	assert.Equal(t, location.ArtifactLocation.URI, "testdata/struct_notmatch.go")
+	assert.Equal(t, location.Region.StartLine, 47)
+	assert.Equal(t, location.Region.StartColumn, 44)
+	assert.Equal(t, len(result.RelatedLocations), 1)
+	assert.Equal(t, result.RelatedLocations[0].PhysicalLocation.Region.StartLine, 26)
+	assert.Equal(t, result.RelatedLocations[0].Message.Text, "model Product")
+}
+
+func TestRulesComplete(t *testing.T) {
+	for _, id := range []string{RuleDifferentStruct, RuleInvalidField, RuleInvalidStructField, RuleParamConflict,
+		RuleAmbiguousBrick, RuleInvalidTag, RuleInvalidValue, RuleInvalidOperator, RuleDifferentRecord, RuleInvalidRelation,
+		RuleForeignKeyType, RuleNoPrimaryKey, RuleUnknownDriver, RuleMissingDriver, RuleDiscardedResult, RuleUnbalancedPop,
+		RuleNestedTerminal, RuleUnusedIgnore, RuleError} {
+		index, _ := ruleInfo(id)
+		assert.NotEqual(t, index, -1, id)
+	}
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// ElemSite is a *ToyBrick expression that store in map or slice,
+// Key is the constant index of element or empty when index is unknown
+type ElemSite struct {
+	BrickSite
+	Key string
+}
+
+// is the type map or slice or array of *ToyBrick
+func (w *Walker) isBrickContainer(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch x := t.Underlying().(type) {
+	case *types.Map:
+		return w.isBrickType(x.Elem())
+	case *types.Slice:
+		return w.isBrickType(x.Elem())
+	case *types.Array:
+		return w.isBrickType(x.Elem())
+	}
+	return false
+}
+
+func isField(obj types.Object) bool {
+	v, ok := obj.(*types.Var)
+	return ok && v.IsField()
+}
+
+// get the object of variable or struct field
+func (w *Walker) exprObject(expr ast.Expr) types.Object {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return w.exprObject(x.X)
+	case *ast.StarExpr:
+		return w.exprObject(x.X)
+	}
+	if ident := getIdent(expr); ident != nil {
+		if obj := w.Info.Uses[ident]; obj != nil {
+			return obj
+		}
+		return w.Info.Defs[ident]
+	}
+	return nil
+}
+
+// the constant index of map or slice
+func (w *Walker) indexKey(index ast.Expr) string {
+	if tv, ok := w.Info.Types[index]; ok && tv.Value != nil {
+		return tv.Value.ExactString()
+	}
+	return ""
+}
+
+// get brick context of object, the struct field context without assignment in current function use
+// the field assignments of whole package that collected by previous walk
+func (w *Walker) objContext(obj types.Object) (TypesStructList, []TypesStructList) {
+	if ctxList, ok := w.BrickAmbiguous[obj]; ok {
+		return nil, ctxList
+	}
+	if ctx, ok := w.BrickIdentCache[obj]; ok {
+		return ctx, nil
+	}
+	if isField(obj) {
+		if sites := w.PrevFieldSites[obj.(*types.Var)]; len(sites) != 0 {
+			return sitesAmbiguous(sites)
+		}
+	}
+	return nil, nil
+}
+
+// get context of m[key] or s[i]
+func (w *Walker) elemContext(index *ast.IndexExpr) (TypesStructList, []TypesStructList) {
+	obj := w.exprObject(index.X)
+	if obj == nil {
+		return nil, nil
+	}
+	return w.containerContext(obj, w.indexKey(index.Index))
+}
+
+// get context of container elements with key, all elements are used if key is empty or never stored
+func (w *Walker) containerContext(obj types.Object, key string) (TypesStructList, []TypesStructList) {
+	var sites []BrickSite
+	if key != "" {
+		for _, site := range w.PrevElemSites[obj] {
+			if site.Key == key {
+				sites = append(sites, site.BrickSite)
+			}
+		}
+	}
+	if len(sites) == 0 {
+		for _, site := range w.PrevElemSites[obj] {
+			sites = append(sites, site.BrickSite)
+		}
+	}
+	if len(sites) == 0 {
+		return nil, nil
+	}
+	return sitesAmbiguous(sites)
+}
+
+// get the context all sites agree with or the different contexts
+func sitesAmbiguous(sites []BrickSite) (TypesStructList, []TypesStructList) {
+	if ctx, ok := sitesContext(sites); ok {
+		return ctx, nil
+	}
+	var ctxList []TypesStructList
+Sites:
+	for _, site := range sites {
+		for _, ctx := range ctxList {
+			if ctx.Equal(site.Ctx) {
+				continue Sites
+			}
+		}
+		ctxList = append(ctxList, site.Ctx)
+	}
+	return nil, ctxList
+}
+
+// record *ToyBrick that store in struct field
+func (w *Walker) recordFieldSite(obj types.Object, expr ast.Expr, ctx TypesStructList) {
+	if isField(obj) && len(ctx) != 0 {
+		field := obj.(*types.Var)
+		w.FieldSites[field] = append(w.FieldSites[field], BrickSite{expr, ctx})
+	}
+}
+
+// record *ToyBrick that store in map or slice
+func (w *Walker) recordElemSite(obj types.Object, key string, expr ast.Expr) {
+	if ctx := w.exprContext(expr); len(ctx) != 0 {
+		w.ElemSites[obj] = append(w.ElemSites[obj], ElemSite{BrickSite{expr, ctx}, key})
+	}
+}
+
+// for the declarations
+// m["product"] = toy.Model(&Product{})
+func (w *Walker) cacheIndexAssign(index *ast.IndexExpr, rhs ast.Expr) {
+	if w.isBrickType(w.Info.TypeOf(index)) == false {
+		return
+	}
+	if obj := w.exprObject(index.X); obj != nil {
+		w.recordElemSite(obj, w.indexKey(index.Index), rhs)
+	}
+}
+
+// for the declarations
+// bricks := []*toyorm.ToyBrick{toy.Model(&Product{})}
+// bricks = append(bricks, toy.Model(&Product{}))
+func (w *Walker) cacheContainerAssign(obj types.Object, rhs ast.Expr) {
+	if obj == nil || w.isBrickContainer(obj.Type()) == false {
+		return
+	}
+	switch x := rhs.(type) {
+	case *ast.CompositeLit:
+		w.recordContainerLit(obj, x)
+	case *ast.CallExpr:
+		if ident, ok := x.Fun.(*ast.Ident); ok && ident.Name == "append" {
+			if _, ok := w.Info.Uses[ident].(*types.Builtin); ok && x.Ellipsis == 0 {
+				for _, arg := range x.Args[1:] {
+					w.recordElemSite(obj, "", arg)
+				}
+			}
+		}
+	}
+}
+
+func (w *Walker) recordContainerLit(obj types.Object, lit *ast.CompositeLit) {
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			w.recordElemSite(obj, w.indexKey(kv.Key), kv.Value)
+		} else {
+			w.recordElemSite(obj, "", elt)
+		}
+	}
+}
+
+// for the declarations
+// repo := &ProductRepo{brick: toy.Model(&Product{})}
+func (w *Walker) cacheStructLit(lit *ast.CompositeLit) {
+	litType := w.Info.TypeOf(lit)
+	if litType == nil {
+		return
+	}
+	structType, ok := litType.Underlying().(*types.Struct)
+	if ok == false {
+		return
+	}
+	for i, elt := range lit.Elts {
+		var field types.Object
+		value := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				field = w.Info.Uses[key]
+			}
+			value = kv.Value
+		} else if i < structType.NumFields() {
+			field = structType.Field(i)
+		}
+		if field == nil {
+			continue
+		}
+		if w.isBrickType(field.Type()) {
+			w.recordFieldSite(field, value, w.exprContext(value))
+		} else if containerLit, ok := value.(*ast.CompositeLit); ok && w.isBrickContainer(field.Type()) {
+			w.recordContainerLit(field, containerLit)
+		}
+	}
+}
+
+// for the declarations
+// for _, brick := range bricks
+func (w *Walker) cacheRangeBrick(stmt *ast.RangeStmt) {
+	if stmt.Value == nil || w.isBrickContainer(w.Info.TypeOf(stmt.X)) == false {
+		return
+	}
+	valueObj := w.exprObject(stmt.Value)
+	container := w.exprObject(stmt.X)
+	if valueObj == nil || container == nil {
+		return
+	}
+	ctx, ctxList := w.containerContext(container, "")
+	w.setBrickContexts(valueObj, ctx, ctxList)
+}
+
+// is element context changed between two walk
+func elemSitesChanged(prev, current map[types.Object][]ElemSite) bool {
+	if len(prev) != len(current) {
+		return true
+	}
+	contain := func(sites []ElemSite, site ElemSite) bool {
+		for _, s := range sites {
+			if s.Key == site.Key && s.Ctx.Equal(site.Ctx) {
+				return true
+			}
+		}
+		return false
+	}
+	for obj, sites := range current {
+		for _, site := range sites {
+			if contain(prev[obj], site) == false {
+				return true
+			}
+		}
+		for _, site := range prev[obj] {
+			if contain(sites, site) == false {
+				return true
+			}
+		}
+	}
+	return false
+}
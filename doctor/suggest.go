@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// max number of "did you mean" suggestions
+const maxSuggestions = 3
+
+// the field names (include alias and embedded fields) close to name
+func fieldSuggestions(name string, fieldMap map[string]*types.Var) []string {
+	var candidates []string
+	for k := range fieldMap {
+		candidates = append(candidates, k)
+	}
+	names := closestNames(name, candidates)
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
+
+// e.g `, did you mean "Name", "Nme" or "Data"?`
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var quoted []string
+	for _, name := range suggestions {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf(", did you mean %s?", quoted[0])
+	}
+	return fmt.Sprintf(", did you mean %s or %s?", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+// e.g `, did you mean "Name" or "Data"?, it is a field of Product in the same chain`
+func (e ErrInvalidField) hint() string {
+	s := didYouMean(e.Suggestions)
+	if e.Other != nil {
+		s += fmt.Sprintf(", it is a field of %s in the same chain", e.Other.Obj().Name())
+	}
+	return s
+}
+
+// all models appear in the brick chain of call, e.g Product and Detail in
+// toy.Model(&Product{}).Preload(Offsetof(Product{}.Detail)).Enter().OrderBy(...)
+func (w *Walker) chainModels(call *ast.CallExpr, ctx TypesStructList) []*types.Named {
+	var models []*types.Named
+	exist := map[*types.Named]bool{}
+	add := func(l TypesStructList) {
+		for _, t := range l {
+			if !exist[t] {
+				exist[t] = true
+				models = append(models, t)
+			}
+		}
+	}
+	add(ctx)
+	var expr ast.Expr = call
+	for {
+		c, ok := expr.(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		add(w.BrickCallCache[c])
+		sel, ok := c.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		expr = sel.X
+		if paren, ok := expr.(*ast.ParenExpr); ok {
+			expr = paren.X
+		}
+	}
+	return models
+}
+
+// add note to the invalid field errors of args when the field belong to other model in the chain
+func (w *Walker) noteChainField(call *ast.CallExpr, ctx TypesStructList, args ...ast.Expr) {
+	var models []*types.Named
+	for _, arg := range args {
+		ast.Inspect(arg, func(node ast.Node) bool {
+			lit, ok := node.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			errs := w.ErrorExpr[lit]
+			for i, err := range errs {
+				e, ok := err.(ErrInvalidField)
+				if !ok || e.Other != nil {
+					continue
+				}
+				name, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					continue
+				}
+				if models == nil {
+					models = w.chainModels(call, ctx)
+				}
+				for _, model := range models {
+					if model == e.Source {
+						continue
+					}
+					fieldMap, err := getStructFieldMap(model.Underlying().(*types.Struct), w.FS)
+					if err != nil {
+						continue
+					}
+					if _, ok := fieldMap[name]; ok {
+						e.Other = model
+						errs[i] = e
+						break
+					}
+				}
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,29 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFieldSuggestions(t *testing.T) {
+	walk := walkTestFile(t, "testdata/suggest.go")
+	var messages []string
+	for _, d := range walk.Diagnostics() {
+		assert.Equal(t, d.Rule(), RuleInvalidField)
+		messages = append(messages, d.Message())
+	}
+	assert.Equal(t, messages, []string{
+		`field not found in Product, did you mean "cost"?`,
+		`field not found in Product, did you mean "CreatedAt"?`,
+		`field not found in Detail, did you mean "Page" or "Tag"?`,
+		`field not found in Detail, it is a field of Product in the same chain`,
+		`field not found in Product, it is a field of Detail in the same chain`,
+		`field not found in Product`,
+	})
+}
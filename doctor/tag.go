@@ -0,0 +1,177 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const RuleInvalidTag = "invalid-tag"
+
+// toyorm tag error, e.g unknown key or auto_increment in string field
+type ErrInvalidTag struct {
+	FileSet *token.FileSet
+	Tag     *ast.BasicLit
+	Msg     string
+}
+
+func (e ErrInvalidTag) Error() string {
+	return fmt.Sprintf("%s %s", relPosition(e.FileSet, e.Tag.Pos()), e.Msg)
+}
+
+func (e ErrInvalidTag) Rule() string    { return RuleInvalidTag }
+func (e ErrInvalidTag) Pos() token.Pos  { return e.Tag.Pos() }
+func (e ErrInvalidTag) End() token.Pos  { return e.Tag.End() }
+func (e ErrInvalidTag) Message() string { return e.Msg }
+
+type tagValueKind int
+
+const (
+	tagNoValue tagValueKind = iota
+	tagOptionalValue
+	tagRequireValue
+)
+
+// all key toyorm tag supported, key is lower case
+var toyormTagKeys = map[string]tagValueKind{
+	"primary key":    tagNoValue,
+	"auto_increment": tagNoValue,
+	"autoincrement":  tagNoValue,
+	"index":          tagOptionalValue,
+	"unique index":   tagOptionalValue,
+	"type":           tagRequireValue,
+	"default":        tagRequireValue,
+	"alias":          tagRequireValue,
+	"foreign key":    tagNoValue,
+	"join":           tagRequireValue,
+	"belong to":      tagRequireValue,
+	"one to one":     tagRequireValue,
+	"one to many":    tagRequireValue,
+	"ignore":         tagNoValue,
+	"-":              tagNoValue,
+	"null":           tagNoValue,
+	"not null":       tagNoValue,
+}
+
+// TagItem is the key value pair in toyorm tag, e.g alias:name
+type TagItem struct {
+	Key    string
+	Val    string
+	HasVal bool
+}
+
+// parse toyorm tag same as toyorm.GetTagKeyVal but keep the empty and value-less items
+func parseToyormTag(tag string) []TagItem {
+	var items []TagItem
+	for i, s := range strings.Split(tag, ";") {
+		s = strings.TrimSpace(s)
+		// allow the last ;
+		if s == "" && i == strings.Count(tag, ";") {
+			continue
+		}
+		item := TagItem{}
+		if idx := strings.Index(s, ":"); idx != -1 {
+			item.Key = strings.ToLower(strings.TrimSpace(s[:idx]))
+			item.Val = strings.TrimSpace(s[idx+1:])
+			item.HasVal = true
+		} else {
+			item.Key = strings.ToLower(s)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// get the toyorm tag of field, ok is false if field hasn't toyorm tag
+func fieldToyormTag(field *ast.Field) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	return reflect.StructTag(tag).Lookup("toyorm")
+}
+
+// check all toyorm tag in struct
+func (w *Walker) checkStructTags(structType *ast.StructType) {
+	for _, field := range structType.Fields.List {
+		tag, ok := fieldToyormTag(field)
+		if ok == false {
+			continue
+		}
+		w.markExpr(field.Tag)
+		w.CheckedExpr[field.Tag] = struct{}{}
+		for _, msg := range checkToyormTag(tag, w.Info.TypeOf(field.Type)) {
+			w.ErrorExpr[field.Tag] = append(w.ErrorExpr[field.Tag], ErrInvalidTag{w.FS, field.Tag, msg})
+		}
+	}
+}
+
+// check toyorm tag with the field type, return the error messages
+func checkToyormTag(tag string, fieldType types.Type) []string {
+	var msgs []string
+	keys := map[string]TagItem{}
+	for _, item := range parseToyormTag(tag) {
+		if item.Key == "" {
+			msgs = append(msgs, fmt.Sprintf("toyorm tag have empty key in %q", tag))
+			continue
+		}
+		kind, ok := toyormTagKeys[item.Key]
+		if ok == false {
+			msgs = append(msgs, fmt.Sprintf("unknown toyorm tag key %q", item.Key))
+			continue
+		}
+		if _, ok := keys[item.Key]; ok {
+			msgs = append(msgs, fmt.Sprintf("duplicate toyorm tag key %q", item.Key))
+			continue
+		}
+		keys[item.Key] = item
+		switch {
+		case kind == tagNoValue && item.HasVal:
+			msgs = append(msgs, fmt.Sprintf("toyorm tag key %q not accept value", item.Key))
+		case kind == tagRequireValue && item.Val == "":
+			msgs = append(msgs, fmt.Sprintf("toyorm tag key %q require value, e.g %s:<value>", item.Key, item.Key))
+		}
+	}
+	// contradictions
+	_, autoIncrement := keys["auto_increment"]
+	if _, ok := keys["autoincrement"]; ok {
+		autoIncrement = true
+	}
+	if autoIncrement && fieldType != nil && isIntegerType(fieldType) == false {
+		msgs = append(msgs, fmt.Sprintf("auto_increment field must be integer but it's %s", fieldType))
+	}
+	_, null := keys["null"]
+	_, notNull := keys["not null"]
+	if null && notNull {
+		msgs = append(msgs, "toyorm tag key \"null\" conflict with \"not null\"")
+	}
+	_, ignore := keys["ignore"]
+	if _, ok := keys["-"]; ok {
+		ignore = true
+	}
+	if ignore && len(keys) > 1 {
+		msgs = append(msgs, "ignored field should not have other toyorm tag key")
+	}
+	return msgs
+}
+
+func isIntegerType(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/types"
+	"testing"
+)
+
+func TestParseToyormTag(t *testing.T) {
+	items := parseToyormTag("primary key; Alias:name ;index:;")
+	assert.Equal(t, []TagItem{
+		{Key: "primary key"},
+		{Key: "alias", Val: "name", HasVal: true},
+		{Key: "index", HasVal: true},
+	}, items)
+	assert.Equal(t, []TagItem{{}, {Key: "index"}}, parseToyormTag(";index"))
+	assert.Empty(t, parseToyormTag(""))
+}
+
+func TestCheckToyormTag(t *testing.T) {
+	intType := types.Typ[types.Uint32]
+	strType := types.Typ[types.String]
+	assert.Empty(t, checkToyormTag("primary key;auto_increment", intType))
+	assert.Empty(t, checkToyormTag("type:VARCHAR(255);default:'';alias:name", strType))
+	assert.Len(t, checkToyormTag("auto_increment", strType), 1)
+	assert.Len(t, checkToyormTag("primary_key", intType), 1)
+	assert.Len(t, checkToyormTag("index;INDEX", intType), 1)
+	assert.Len(t, checkToyormTag("alias", strType), 1)
+	assert.Len(t, checkToyormTag("foreign key:ID", intType), 1)
+	assert.Len(t, checkToyormTag("null;not null", intType), 1)
+	assert.Len(t, checkToyormTag("-;index", intType), 1)
+}
+
+func TestWalkTag(t *testing.T) {
+	walk := walkTestFile(t, "testdata/tag.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleInvalidTag], 7)
+}
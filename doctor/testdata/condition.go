@@ -0,0 +1,63 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Product struct {
+	toyorm.ModelDefault
+	Name  string
+	Price float64
+	Count int
+}
+
+func main() {
+	toy, err := toyorm.Open("sqlite3", "")
+	if err != nil {
+		panic(err)
+	}
+	var name string
+	var value interface{}
+	// normal
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), "pigeon")
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), name)
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), value)
+	_ = toy.Model(&Product{}).Where(">", "Price", 10)
+	_ = toy.Model(&Product{}).Where(toyorm.ExprIn, unsafe.Offsetof(Product{}.Count), []int{1, 2})
+	_ = toy.Model(&Product{}).Where("BETWEEN", unsafe.Offsetof(Product{}.Price), 1, 2.5)
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), "pigeon").Or().
+		Condition("=", unsafe.Offsetof(Product{}.Count), 2)
+
+	// value error
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), 42)
+	_ = toy.Model(&Product{}).Where(">", "Price", "10")
+	_ = toy.Model(&Product{}).Where(">", `Price`, "10")
+	_ = toy.Model(&Product{}).Where("IN", unsafe.Offsetof(Product{}.Count), 2)
+	_ = toy.Model(&Product{}).Where("IN", unsafe.Offsetof(Product{}.Count), []string{"2"})
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), "pigeon").And().
+		Condition("=", unsafe.Offsetof(Product{}.Count), true)
+
+	// normal operator
+	_ = toy.Model(&Product{}).Where(toyorm.ExprNull, unsafe.Offsetof(Product{}.DeletedAt))
+	_ = toy.Model(&Product{}).Where(toyorm.ExprNotLike, unsafe.Offsetof(Product{}.Name), "%pigeon%")
+
+	// operator error
+	_ = toy.Model(&Product{}).Where("==", unsafe.Offsetof(Product{}.Name), "pigeon")
+	_ = toy.Model(&Product{}).Where("LIKEE", unsafe.Offsetof(Product{}.Name), "pigeon")
+	_ = toy.Model(&Product{}).Where(toyorm.ExprAnd, unsafe.Offsetof(Product{}.Name), "pigeon")
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name))
+	_ = toy.Model(&Product{}).Where("NULL", unsafe.Offsetof(Product{}.Name), "pigeon")
+	_ = toy.Model(&Product{}).Where("BETWEEN", unsafe.Offsetof(Product{}.Price), 1, 2, 3)
+	_ = toy.Model(&Product{}).Where("BETWEEN", unsafe.Offsetof(Product{}.Price), 1)
+	_ = toy.Model(&Product{}).Where("BETWEEN", unsafe.Offsetof(Product{}.Price), [3]int{1, 2, 3})
+	_ = toy.Model(&Product{}).Where("not in", unsafe.Offsetof(Product{}.Count), []int{1, 2})
+	_ = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), "pigeon").Or().
+		Condition("=>", unsafe.Offsetof(Product{}.Count), 2)
+}
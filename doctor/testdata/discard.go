@@ -0,0 +1,39 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Product struct {
+	toyorm.ModelDefault
+	Name string
+}
+
+func Discard(toy *toyorm.Toy) {
+	brick := toy.Model(&Product{})
+	// discarded
+	brick.OrderBy(unsafe.Offsetof(Product{}.Name))
+	brick.Debug().Limit(2)
+	toy.Model(&Product{}).Debug()
+	brick.Or().Condition("=", unsafe.Offsetof(Product{}.Name), "pigeon")
+	_ = brick.Offset(2)
+	// not brick chain method
+	toy.Model(&Product{})
+	brick.Or()
+	// explicit discard of the new brick
+	_ = toy.Model(&Product{}).Debug()
+	// used
+	brick = brick.OrderBy(unsafe.Offsetof(Product{}.Name))
+	limited := brick.Limit(2)
+	var tab []Product
+	limited.Find(&tab)
+	// limited isn't read any more, no fix
+	limited.Offset(2)
+}
@@ -0,0 +1,23 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+)
+
+const driverName = "mysql"
+
+func Driver(name string) {
+	// misspelled
+	_, _ = toyorm.Open("sqlit3", "")
+	_, _ = toyorm.Open("oracle", "")
+	// driver package not imported
+	_, _ = toyorm.Open(driverName, "")
+	// not a constant
+	_, _ = toyorm.Open(name, "")
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+	Data      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Price  int `toyorm:"alias:cost"`
+	Detail Detail
+}
+
+func Fix(toy *toyorm.Toy) {
+	// fix Detail to Product
+	_ = toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{}.Name))
+	// Product has no field Data, can't fix
+	_ = toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{}.Data))
+	// fix misspelled field
+	_ = toy.Model(&Product{}).OrderBy("Nmae")
+	_ = toy.Model(&Product{}).OrderBy("name")
+	_ = toy.Model(&Product{}).OrderBy("cots")
+	// too different, can't fix
+	_ = toy.Model(&Product{}).OrderBy("NotExist")
+	// the elements belong to Detail, can't fix
+	_ = toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{Data: "data"}.Name))
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+func Flow(toy *toyorm.Toy, name string, preload bool, n int) {
+	var brick *toyorm.ToyBrick
+	if name != "" {
+		brick = toy.Model(&Product{}).Where("=", unsafe.Offsetof(Product{}.Name), name)
+	} else {
+		brick = toy.Model(&Product{})
+	}
+	// normal, both branches are Product
+	brick = brick.OrderBy(unsafe.Offsetof(Product{}.Name))
+	// field error, assignment in branch is kept
+	brick.OrderBy(unsafe.Offsetof(Detail{}.Name))
+
+	if preload {
+		brick = brick.Preload(unsafe.Offsetof(Product{}.Detail))
+	}
+	// ambiguous, brick may be Product or Product->Detail
+	brick.OrderBy(unsafe.Offsetof(Product{}.Name))
+
+	other := toy.Model(&Product{})
+	if n == 0 {
+		panic("n must be positive")
+	}
+	switch n {
+	case 1:
+		other = other.Limit(1)
+	default:
+		other = other.Limit(n)
+	}
+	// normal, the terminated branch is ignored
+	other.OrderBy(unsafe.Offsetof(Product{}.Name))
+
+	loop := toy.Model(&Product{})
+	for i := 0; i < n; i++ {
+		// ambiguous at second loop
+		loop.OrderBy(unsafe.Offsetof(Product{}.Name))
+		loop = loop.Preload(unsafe.Offsetof(Product{}.Detail))
+	}
+}
+
+func FlowBranch(toy *toyorm.Toy, model interface{}, n int) {
+	brick := toy.Model(&Product{})
+	if n > 0 {
+		brick = toy.Model(model)
+	}
+	// normal, brick is unknown after the branch without model
+	brick = brick.OrderBy(unsafe.Offsetof(Detail{}.Name))
+
+	fall := toy.Model(&Product{})
+	switch n {
+	case 1:
+		fall = fall.Preload(unsafe.Offsetof(Product{}.Detail))
+		fallthrough
+	case 2:
+		// ambiguous, fallthrough from Product->Detail
+		fall = fall.OrderBy(unsafe.Offsetof(Product{}.Name))
+	}
+
+	leave := toy.Model(&Product{})
+	switch n {
+	case 3:
+		leave = leave.Preload(unsafe.Offsetof(Product{}.Detail))
+		break
+	}
+	// ambiguous, break leave the switch with Product->Detail
+	leave = leave.OrderBy(unsafe.Offsetof(Product{}.Name))
+
+	exit := toy.Model(&Product{})
+	for i := 0; i < n; i++ {
+		if i == 2 {
+			exit = exit.Preload(unsafe.Offsetof(Product{}.Detail))
+			break
+		}
+	}
+	// ambiguous, break leave the loop with Product->Detail
+	exit = exit.OrderBy(unsafe.Offsetof(Product{}.Name))
+
+	next := toy.Model(&Product{})
+	for i := 0; i < n; i++ {
+		// ambiguous at second loop, continue with Product->Detail
+		next = next.OrderBy(unsafe.Offsetof(Product{}.Name))
+		if i == 2 {
+			next = next.Preload(unsafe.Offsetof(Product{}.Detail))
+			continue
+		}
+	}
+}
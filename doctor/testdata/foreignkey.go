@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type ProductKey uint32
+
+type Product struct {
+	toyorm.ModelDefault
+	Detail   Detail
+	Owner    *User
+	OwnerID  int
+	Comments []Comment
+	Note     Note
+	Log      Log
+	Tags     []Tag
+}
+
+type Detail struct {
+	ID        uint32
+	ProductID int
+}
+
+type User struct {
+	ID uint32
+}
+
+type Comment struct {
+	ID        uint32
+	ProductID string
+}
+
+// null relation and named type are ok
+type Note struct {
+	ID        uint32
+	ProductID *uint32
+}
+
+type Log struct {
+	ID        uint32
+	ProductID ProductKey
+}
+
+type Tag struct {
+	Name string
+}
+
+func ForeignKey(toy *toyorm.Toy) {
+	brick := toy.Model(&Product{})
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Detail)).Enter()
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Owner)).Enter()
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Comments)).Enter()
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Note)).Enter()
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Log)).Enter()
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Tags)).Enter()
+	var tags []Tag
+	toy.Model(&Tag{}).Find(&tags)
+}
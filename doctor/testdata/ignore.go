@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+	Note   string `toyorm:"indexx"` //toy-doctor:ignore invalid-tag legacy tag
+}
+
+func Ignore(toy *toyorm.Toy) {
+	// trailing directive
+	_ = toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{}.Name)) //toy-doctor:ignore different-struct
+
+	// directive on previous line ignore the whole statement
+	//toy-doctor:ignore generic helper
+	_ = toy.Model(&Product{}).
+		OrderBy("NotExist").
+		OrderBy(unsafe.Offsetof(Detail{}.Name))
+
+	// rule not match, still report
+	_ = toy.Model(&Product{}).OrderBy("NotExist") //toy-doctor:ignore different-struct
+
+	// stale directive
+	//toy-doctor:ignore invalid-field
+	_ = toy.Model(&Product{}).OrderBy("Name")
+
+	// spaced directive only work in doc comment
+	// toy-doctor:ignore different-struct
+	_ = toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{}.Name))
+}
+
+// toy-doctor:ignore the whole function
+func IgnoreFunc(toy *toyorm.Toy) {
+	_ = toy.Model(&Product{}).OrderBy(unsafe.Offsetof(Detail{}.Name))
+	_ = toy.Model(&Product{}).OrderBy("NotExist")
+}
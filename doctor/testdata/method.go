@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+func main() {
+	toy, err := toyorm.Open("sqlite3", "")
+	if err != nil {
+		panic(err)
+	}
+	brick := toy.Model(&Product{})
+	// method value
+	orderBy := brick.OrderBy
+	_ = orderBy(unsafe.Offsetof(Product{}.Name))
+	// field error
+	_ = orderBy(unsafe.Offsetof(Detail{}.Name))
+
+	// method value chain
+	limit := brick.Limit
+	_ = limit(2).OrderBy(unsafe.Offsetof(Product{}.Name))
+
+	// method expression
+	_ = (*toyorm.ToyBrick).OrderBy(brick, unsafe.Offsetof(Product{}.Name))
+	_ = (*toyorm.ToyBrick).Preload(brick, unsafe.Offsetof(Product{}.Detail)).OrderBy(unsafe.Offsetof(Detail{}.Name))
+	// field error
+	_ = (*toyorm.ToyBrick).OrderBy(brick, unsafe.Offsetof(Detail{}.Name))
+
+	// method expression in variable
+	where := (*toyorm.ToyBrick).Where
+	// field error
+	_ = where(brick, "=", unsafe.Offsetof(Detail{}.Name), "pigeon")
+}
+
+func MethodReassign(toy *toyorm.Toy) {
+	brick := toy.Model(&Product{})
+	orderBy := brick.OrderBy
+	other := orderBy
+	// field error, other is copy of orderBy
+	_ = other(unsafe.Offsetof(Detail{}.Name))
+	orderBy = func(vList ...toyorm.FieldSelection) *toyorm.ToyBrick {
+		return toy.Model(&Detail{}).OrderBy(vList...)
+	}
+	// normal, orderBy isn't the method value of Product brick any more
+	_ = orderBy(unsafe.Offsetof(Detail{}.Name))
+}
+
+func MethodBranch(toy *toyorm.Toy, c bool) {
+	brick := toy.Model(&Product{})
+	orderBy := toy.Model(&Detail{}).OrderBy
+	if c {
+		orderBy = brick.OrderBy
+	} else {
+		// normal, the method value assigned in then branch don't leak to else branch
+		_ = orderBy(unsafe.Offsetof(Detail{}.Name))
+	}
+	// normal, orderBy is the method of Product or Detail brick
+	_ = orderBy(unsafe.Offsetof(Detail{}.Name))
+}
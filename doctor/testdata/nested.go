@@ -0,0 +1,42 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+func Nested(toy *toyorm.Toy) {
+	var products []Product
+	brick := toy.Model(&Product{})
+	// balanced
+	brick = brick.Preload(unsafe.Offsetof(Product{}.Detail)).Enter()
+	brick.Find(&products)
+	// pop past root
+	brick = brick.Enter()
+	brick = toy.Model(&Product{}).Preload(unsafe.Offsetof(Product{}.Detail)).Enter().Swap()
+	// still in Detail context
+	toy.Model(&Product{}).Preload(unsafe.Offsetof(Product{}.Detail)).Count()
+	detailBrick := brick.Preload(unsafe.Offsetof(Product{}.Detail)).OrderBy(unsafe.Offsetof(Detail{}.Name))
+	var details []Detail
+	detailBrick.Find(&details)
+	// leave the nested context before Find
+	detailBrick.Enter().Find(&products)
+}
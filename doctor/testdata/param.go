@@ -0,0 +1,49 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Data      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+func OrderProduct(brick *toyorm.ToyBrick) *toyorm.ToyBrick {
+	// field error
+	return brick.OrderBy(unsafe.Offsetof(Detail{}.Data))
+}
+
+func OrderProductByName(brick *toyorm.ToyBrick) {
+	OrderProduct(brick.OrderBy(unsafe.Offsetof(Product{}.Name)))
+}
+
+// call sites have different model
+func Conflict(brick *toyorm.ToyBrick) {
+	brick.OrderBy(unsafe.Offsetof(Product{}.Name))
+}
+
+func main() {
+	toy, err := toyorm.Open("sqlite3", "")
+	if err != nil {
+		panic(err)
+	}
+	brick := toy.Model(&Product{})
+	OrderProductByName(brick)
+	Conflict(brick)
+	Conflict(toy.Model(&Detail{}))
+}
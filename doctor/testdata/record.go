@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+func main() {
+	toy, err := toyorm.Open("sqlite3", "")
+	if err != nil {
+		panic(err)
+	}
+	brick := toy.Model(&Product{})
+	var products []Product
+	var productPtrs []*Product
+	var product Product
+	var details []Detail
+	var record interface{}
+	// normal
+	_, _ = brick.Find(&products)
+	_, _ = brick.Find(&productPtrs)
+	_, _ = brick.Insert(&product)
+	_, _ = brick.Save(products)
+	_, _ = brick.Update(map[string]interface{}{"Name": "pigeon"})
+	_, _ = brick.Delete(record)
+	_, _ = brick.Preload(unsafe.Offsetof(Product{}.Detail)).Find(&details)
+
+	// record error
+	_, _ = brick.Find(&details)
+	_, _ = brick.Insert(&Detail{})
+	_, _ = brick.USave(details)
+	_, _ = brick.Find(&product.Name)
+	_, _ = brick.Preload(unsafe.Offsetof(Product{}.Detail)).Enter().Delete(&details)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Product struct {
+	toyorm.ModelDefault
+	Name string
+	// one to one
+	Detail Detail
+	// belong to
+	Owner   *User
+	OwnerID uint32
+	// many to many
+	Tags []Tag
+	// one to many with specified field
+	Comments []Comment `toyorm:"one to many:PID"`
+	// relation field with alias and foreign key tag
+	Note Note
+	Info Info
+	// no relation
+	Shop  *Shop
+	Extra Extra `toyorm:"one to one:ProductID"`
+}
+
+type Detail struct {
+	ID        uint32 `toyorm:"primary key"`
+	ProductID uint32
+}
+
+type User struct {
+	ID   uint32
+	Name string
+}
+
+type Tag struct {
+	ID   uint32
+	Name string
+}
+
+type Comment struct {
+	ID        uint32
+	ProductID uint32
+}
+
+type Note struct {
+	ID  uint32
+	PID uint32 `toyorm:"alias:ProductID"`
+}
+
+type Info struct {
+	ID    uint32
+	Owner uint32 `toyorm:"foreign key"`
+}
+
+type Shop struct {
+	ID   int
+	Name string
+}
+
+type Extra struct {
+	ID   uint32
+	Name string
+}
+
+func Relation(toy *toyorm.Toy) {
+	brick := toy.Model(&Product{})
+	_ = brick.Preload(unsafe.Offsetof(Product{}.Detail))
+	_ = brick.Preload(unsafe.Offsetof(Product{}.Owner))
+	_ = brick.Preload(unsafe.Offsetof(Product{}.Tags))
+	_ = brick.Preload(unsafe.Offsetof(Product{}.Note))
+	_ = brick.Preload("Info")
+	_ = brick.Preload(unsafe.Offsetof(Product{}.Comments))
+	_ = brick.Preload(unsafe.Offsetof(Product{}.Shop))
+	_ = brick.Preload("Extra")
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+func productQuery(toy *toyorm.Toy) *toyorm.ToyBrick {
+	return toy.Model(&Product{}).Debug()
+}
+
+func productDetailQuery(toy *toyorm.Toy) *toyorm.ToyBrick {
+	return productQuery(toy).Preload(unsafe.Offsetof(Product{}.Detail))
+}
+
+func productQueryWithErr(toy *toyorm.Toy) (*toyorm.ToyBrick, error) {
+	return productQuery(toy), nil
+}
+
+func main() {
+	toy, err := toyorm.Open("sqlite3", "")
+	if err != nil {
+		panic(err)
+	}
+	// normal
+	_ = productQuery(toy).OrderBy(unsafe.Offsetof(Product{}.Name))
+	_ = productDetailQuery(toy).OrderBy(unsafe.Offsetof(Detail{}.Name))
+	_ = productDetailQuery(toy).Enter().OrderBy(unsafe.Offsetof(Product{}.Name))
+
+	// field error
+	_ = productQuery(toy).OrderBy(unsafe.Offsetof(Detail{}.Name))
+	_ = productDetailQuery(toy).OrderBy(unsafe.Offsetof(Product{}.Name))
+	brick, err := productQueryWithErr(toy)
+	if err != nil {
+		panic(err)
+	}
+	brick.OrderBy(unsafe.Offsetof(Detail{}.Name))
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Name      string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Detail Detail
+}
+
+type ProductRepo struct {
+	brick    *toyorm.ToyBrick
+	registry map[string]*toyorm.ToyBrick
+}
+
+func NewProductRepo(toy *toyorm.Toy) *ProductRepo {
+	return &ProductRepo{
+		brick: toy.Model(&Product{}),
+		registry: map[string]*toyorm.ToyBrick{
+			"product": toy.Model(&Product{}),
+			"detail":  toy.Model(&Detail{}),
+		},
+	}
+}
+
+func (r *ProductRepo) List() {
+	// normal
+	r.brick.OrderBy(unsafe.Offsetof(Product{}.Name))
+	r.registry["detail"].OrderBy(unsafe.Offsetof(Detail{}.Name))
+	// field error
+	r.brick.OrderBy(unsafe.Offsetof(Detail{}.Name))
+	r.registry["product"].OrderBy(unsafe.Offsetof(Detail{}.Name))
+}
+
+func (r *ProductRepo) Any(name string) {
+	// ambiguous, registry contain Product and Detail
+	r.registry[name].OrderBy(unsafe.Offsetof(Product{}.Name))
+}
+
+func Slice(toy *toyorm.Toy) {
+	var bricks []*toyorm.ToyBrick
+	bricks = append(bricks, toy.Model(&Product{}))
+	for _, brick := range bricks {
+		// field error
+		brick.OrderBy(unsafe.Offsetof(Detail{}.Name))
+	}
+	brick := bricks[0]
+	// field error
+	brick.OrderBy(unsafe.Offsetof(Detail{}.Name))
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID        uint32
+	ProductID uint32
+	Page      int
+	Tag       string
+	Color     string
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string
+	Price  int `toyorm:"alias:cost"`
+	Detail Detail
+}
+
+func Suggest(toy *toyorm.Toy) {
+	// alias name
+	_ = toy.Model(&Product{}).OrderBy("cots")
+	// embedded field
+	_ = toy.Model(&Product{}).OrderBy("CreateAt")
+	// more than one candidate
+	_ = toy.Model(&Product{}).Preload(unsafe.Offsetof(Product{}.Detail)).OrderBy("Pag")
+	// field of Product before Enter
+	_ = toy.Model(&Product{}).Preload(unsafe.Offsetof(Product{}.Detail)).OrderBy("Name")
+	// field of Detail after Enter
+	_ = toy.Model(&Product{}).Preload(unsafe.Offsetof(Product{}.Detail)).Enter().OrderBy("Color")
+	// nothing similar
+	_ = toy.Model(&Product{}).OrderBy("NotExist")
+}
@@ -0,0 +1,30 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+)
+
+type Detail struct {
+	ID        uint32 `toyorm:"primary key;auto_increment"`
+	ProductID uint32 `toyorm:"index:idx_product;foreign key"`
+	Data      string `toyorm:"type:VARCHAR(1024);default:''"`
+	// tag error
+	Name string `toyorm:"primary key;auto_increment"`
+	Tags string `toyorm:"indexx"`
+	Code string `toyorm:"alias;unique index"`
+	Desc string `toyorm:"index;index"`
+	Note string `toyorm:"NULL;NOT NULL;foreign key:ID"`
+	Temp string `toyorm:"-;index"`
+}
+
+type Product struct {
+	toyorm.ModelDefault
+	Name   string `toyorm:"unique index:idx_name" json:"name"`
+	Detail *Detail
+}
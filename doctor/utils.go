@@ -4,15 +4,15 @@
  * license that can be found in the LICENSE file.
  */
 
-package main
+package doctor
 
 import (
 	"errors"
-	"fmt"
 	"github.com/bigpigeon/toyorm"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -102,73 +102,25 @@ func (l TypesStructList) Copy() TypesStructList {
 	return r
 }
 
-func standardSrc() string {
-	brickType := reflect.TypeOf(&toyorm.ToyBrick{})
-	brickOrType := reflect.TypeOf((&toyorm.ToyBrick{}).Or())
-	brickAndType := reflect.TypeOf((&toyorm.ToyBrick{}).And())
-	src := `
-package main
-import "github.com/bigpigeon/toyorm"
-import "unsafe"
-
-type Product struct{
-	toyorm.ModelDefault
-	Data string
-}
-func main(){
-	toy, err := toyorm.Open("sqlite3", "")
-	if err != nil {
-		panic(err)
-	}
-	// this usage with toyorm is error, don't try to use it
-	
-	// toy model
-	brick := toy.Model(&Product{})
-	// chain funcs
-	{
-		%s
-	}
-	// preload
-	_ = brick.Preload
-	// enter
-	_ = brick.Enter
-	// join
-	_ = brick.Join
-	// swap
-	_ = brick.Swap
-	// offsetof
-	_ = unsafe.Offsetof(Product{}.ID)
-
-	
-}
-`
-	var methodList []string
-	for i := 0; i < brickType.NumMethod(); i++ {
-		method := brickType.Method(i)
-		if method.Type.NumOut() == 1 && method.Type.Out(0) == brickType {
-			switch method.Name {
-			case "Preload", "Enter", "Join", "Swap":
-			default:
-				methodList = append(methodList, fmt.Sprintf("_ = brick.%s", method.Name))
-			}
-		}
+func (l TypesStructList) Equal(o TypesStructList) bool {
+	if len(l) != len(o) {
+		return false
 	}
-	methodList = append(methodList, "_ = brick.Or")
-	for i := 0; i < brickOrType.NumMethod(); i++ {
-		method := brickOrType.Method(i)
-		if method.Type.NumOut() == 1 && method.Type.Out(0) == brickType {
-			methodList = append(methodList, fmt.Sprintf("_ = brick.Or().%s", method.Name))
+	for i := range l {
+		if l[i] != o[i] {
+			return false
 		}
 	}
-	methodList = append(methodList, "_ = brick.And")
-	for i := 0; i < brickAndType.NumMethod(); i++ {
-		method := brickAndType.Method(i)
-		if method.Type.NumOut() == 1 && method.Type.Out(0) == brickType {
-			methodList = append(methodList, fmt.Sprintf("_ = brick.And().%s", method.Name))
-		}
+	return true
+}
+
+// e.g Product->Detail when Product preload Detail
+func (l TypesStructList) String() string {
+	var names []string
+	for _, t := range l {
+		names = append(names, t.Obj().Name())
 	}
-	src = fmt.Sprintf(src, strings.Join(methodList, "\n\t\t"))
-	return src
+	return strings.Join(names, "->")
 }
 
 func joinPoint(dir, name string) string {
@@ -184,3 +136,24 @@ func b2i(b bool) int {
 	}
 	return 0
 }
+
+// position with filename relative to the working directory when possible
+func relPosition(fs *token.FileSet, pos token.Pos) token.Position {
+	position := fs.Position(pos)
+	if filepath.IsAbs(position.Filename) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, position.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+				position.Filename = rel
+			}
+		}
+	}
+	return position
+}
+
+// cover profile use import path + file name, absolute file path only keep the base name
+func coverFileName(name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Base(name)
+	}
+	return name
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/go/packages"
+)
+
+// VersionAPI is the chain semantics of toyorm releases since version Since
+type VersionAPI struct {
+	Since string
+	API   APIDescriptor
+}
+
+// VersionTable sorted by Since, add an entry when a toyorm release change
+// the methods push/pop model stack or the api names
+var VersionTable = []VersionAPI{
+	// Preload/Enter is the only model stack, Join and Swap are normal method if they exist
+	{Since: "v0.0.0", API: APIDescriptor{
+		Model:   "Model",
+		Preload: "Preload",
+		Enter:   "Enter",
+		Branch:  []string{"Or", "And"},
+	}},
+	// join query, Join push the joined model and Swap pop it
+	{Since: "v0.5.0", API: DefaultAPI},
+}
+
+// APIForVersion return the rules of toyorm version, the unknown version use the latest rules
+func APIForVersion(version string) VersionAPI {
+	if !semver.IsValid(version) {
+		return VersionTable[len(VersionTable)-1]
+	}
+	rules := VersionTable[0]
+	for _, v := range VersionTable {
+		if semver.Compare(version, v.Since) >= 0 {
+			rules = v
+		}
+	}
+	return rules
+}
+
+// toyorm version required by go.mod of module
+func ModuleToyormVersion(gomod string) (string, error) {
+	data, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseLax(gomod, data, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, req := range f.Require {
+		if req.Mod.Path == ToyormPath {
+			return req.Mod.Version, nil
+		}
+	}
+	return "", nil
+}
+
+// find the go.mod of directory
+func findGoMod(dir string) string {
+	for {
+		gomod := filepath.Join(dir, "go.mod")
+		if info, err := os.Stat(gomod); err == nil && !info.IsDir() {
+			return gomod
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// toyorm version of the module pkg belong to
+func packageToyormVersion(pkg *packages.Package) string {
+	version := ""
+	packages.Visit([]*packages.Package{pkg}, func(p *packages.Package) bool {
+		if p.PkgPath == ToyormPath {
+			if p.Module != nil {
+				version = p.Module.Version
+			}
+			return false
+		}
+		return version == ""
+	}, nil)
+	if version == "" && pkg.Module != nil && pkg.Module.GoMod != "" {
+		version, _ = ModuleToyormVersion(pkg.Module.GoMod)
+	}
+	return version
+}
+
+// describe the rules applied, e.g toyorm >= v0.0.0
+func (v VersionAPI) String() string {
+	return "toyorm >= " + v.Since
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAPIForVersion(t *testing.T) {
+	table := VersionTable
+	defer func() { VersionTable = table }()
+	newAPI := DefaultAPI
+	newAPI.Preload = "With"
+	VersionTable = []VersionAPI{
+		{Since: "v0.0.0", API: DefaultAPI},
+		{Since: "v1.0.0", API: newAPI},
+	}
+	assert.Equal(t, APIForVersion("v0.5.1").Since, "v0.0.0")
+	assert.Equal(t, APIForVersion("v1.0.0").API.Preload, "With")
+	assert.Equal(t, APIForVersion("v1.2.0-20180101000000-abcdefabcdef").Since, "v1.0.0")
+	// unknown version use the latest rules
+	assert.Equal(t, APIForVersion("").Since, "v1.0.0")
+	assert.Equal(t, APIForVersion("").String(), "toyorm >= v1.0.0")
+}
+
+// toyorm before join query, it haven't Join/Swap
+const legacyToyormSrc = `
+package toyorm
+
+type FieldSelection interface{}
+
+type Toy struct{}
+
+func (t *Toy) Model(v interface{}) *ToyBrick { return nil }
+
+type ToyBrick struct{}
+
+type BrickOr struct{}
+
+func (t *ToyBrick) Preload(fv FieldSelection) *ToyBrick       { return t }
+func (t *ToyBrick) Enter() *ToyBrick                          { return t }
+func (t *ToyBrick) OrderBy(vList ...FieldSelection) *ToyBrick { return t }
+func (t *ToyBrick) Or() BrickOr                               { return BrickOr{} }
+func (t *ToyBrick) And() BrickOr                              { return BrickOr{} }
+`
+
+const legacySrc = `
+package main
+
+import (
+	"github.com/bigpigeon/toyorm"
+	"unsafe"
+)
+
+type Detail struct {
+	ID   uint32
+	Name string
+}
+
+type Product struct {
+	ID     uint32
+	Name   string
+	Detail Detail
+}
+
+func Legacy(toy *toyorm.Toy) {
+	_ = toy.Model(&Product{}).Preload(unsafe.Offsetof(Product{}.Detail)).OrderBy(unsafe.Offsetof(Product{}.Name))
+}
+`
+
+func TestVersionTable(t *testing.T) {
+	legacy := APIForVersion("v0.4.2")
+	assert.Equal(t, legacy.Since, "v0.0.0")
+	assert.Equal(t, legacy.API.Join, "")
+	assert.Equal(t, legacy.API.Swap, "")
+	assert.Equal(t, APIForVersion("v0.5.0").API.Join, "Join")
+	assert.Equal(t, APIForVersion("v0.5.1").String(), "toyorm >= v0.5.0")
+	assert.Equal(t, APIForVersion("").API, DefaultAPI)
+
+	// the toyorm haven't join is checked with the rules of its version
+	walk, err := stubWalker(t, legacyToyormSrc, legacySrc, nil, "v0.4.2")
+	if assert.Nil(t, err) {
+		assert.Equal(t, walk.APIRules, "toyorm >= v0.0.0")
+		assert.Nil(t, walk.ToyChainJoin)
+		assert.Equal(t, len(walk.ToyPushMethod), 1)
+		assert.Equal(t, len(walk.ToyPopMethod), 1)
+		walk.Walk()
+		// OrderBy use Product field after Preload Detail
+		assert.Equal(t, diagnosticRules(walk)[RuleDifferentStruct], 1)
+	}
+	_, err = stubWalker(t, legacyToyormSrc, legacySrc, nil, "v0.5.0")
+	assert.NotNil(t, err)
+
+	// Join is a normal chain method without join in api
+	walk, err = stubWalker(t, renamedToyormSrc, renamedSrc, &APIDescriptor{Model: "Model", Preload: "With", Enter: "Enter"}, "")
+	if assert.Nil(t, err) {
+		join := lookupMustMethod(t, walk, "Join")
+		assert.False(t, walk.IsPushMethod(join))
+		assert.True(t, walk.IsBrickChain(join))
+	}
+}
+
+func TestModuleToyormVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toy-doctor")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	gomod := filepath.Join(dir, "go.mod")
+	assert.Nil(t, ioutil.WriteFile(gomod, []byte(`module example.com/shop
+
+require (
+	github.com/bigpigeon/toyorm v0.5.1
+	github.com/mattn/go-sqlite3 v1.9.0
+)
+`), 0644))
+	sub := filepath.Join(dir, "model")
+	assert.Nil(t, os.Mkdir(sub, 0755))
+
+	assert.Equal(t, findGoMod(sub), gomod)
+	version, err := ModuleToyormVersion(gomod)
+	assert.Nil(t, err)
+	assert.Equal(t, version, "v0.5.1")
+}
@@ -0,0 +1,762 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic is the error found by walker, it can be report as analysis.Diagnostic
+type Diagnostic interface {
+	error
+	// rule id of the error e.g different-struct
+	Rule() string
+	// range of the error expression
+	Pos() token.Pos
+	End() token.Pos
+	// error message without position
+	Message() string
+}
+
+const (
+	RuleDifferentStruct    = "different-struct"
+	RuleInvalidField       = "invalid-field"
+	RuleInvalidStructField = "invalid-struct-field"
+)
+
+type ErrDifferentStruct struct {
+	FileSet *token.FileSet
+	Source  *types.Named
+	Target  ast.Expr
+}
+
+func (e ErrDifferentStruct) Error() string {
+	return fmt.Sprintf("%s type must same as %s", relPosition(e.FileSet, e.Target.Pos()), relPosition(e.FileSet, e.Source.Obj().Pos()))
+}
+
+func (e ErrDifferentStruct) Rule() string   { return RuleDifferentStruct }
+func (e ErrDifferentStruct) Pos() token.Pos { return e.Target.Pos() }
+func (e ErrDifferentStruct) End() token.Pos { return e.Target.End() }
+func (e ErrDifferentStruct) Message() string {
+	return fmt.Sprintf("type must same as %s", e.Source.Obj().Name())
+}
+
+type ErrInvalidField struct {
+	FileSet *token.FileSet
+	Source  *types.Named
+	Expr    ast.Expr
+	// the field names close to the invalid field
+	Suggestions []string
+	// the other model in the same chain has this field
+	Other *types.Named
+}
+
+func (e ErrInvalidField) Error() string {
+	return fmt.Sprintf("%s field not found in %s%s", relPosition(e.FileSet, e.Expr.Pos()), relPosition(e.FileSet, e.Source.Obj().Pos()), e.hint())
+}
+
+func (e ErrInvalidField) Rule() string   { return RuleInvalidField }
+func (e ErrInvalidField) Pos() token.Pos { return e.Expr.Pos() }
+func (e ErrInvalidField) End() token.Pos { return e.Expr.End() }
+func (e ErrInvalidField) Message() string {
+	return fmt.Sprintf("field not found in %s%s", e.Source.Obj().Name(), e.hint())
+}
+
+type ErrInvalidStructField struct {
+	FileSet *token.FileSet
+	Expr    ast.Expr
+}
+
+func (e ErrInvalidStructField) Error() string {
+	return fmt.Sprintf("%s is not a struct field", relPosition(e.FileSet, e.Expr.Pos()))
+}
+
+func (e ErrInvalidStructField) Rule() string    { return RuleInvalidStructField }
+func (e ErrInvalidStructField) Pos() token.Pos  { return e.Expr.Pos() }
+func (e ErrInvalidStructField) End() token.Pos  { return e.Expr.End() }
+func (e ErrInvalidStructField) Message() string { return "is not a struct field" }
+
+type Walker struct {
+	FS              *token.FileSet
+	Pkg             *types.Package
+	Toyorm          bool
+	BrickIdentCache map[types.Object]TypesStructList
+	// bricks carry different models on different paths
+	BrickAmbiguous map[types.Object][]TypesStructList
+	// method value or method expression of ToyBrick
+	MethodValues   map[types.Object]MethodValue
+	BrickCallCache map[*ast.CallExpr]TypesStructList
+	Files          []*ast.File
+	Info           *types.Info
+	// importer use to resolve the toyorm package analyzed program imported
+	Importer types.Importer
+	// Toy.Model method
+	ToyModel *types.Func
+	// all ToyBrick method those return type are itself
+	ToyChainMethod map[string]struct{}
+	// all ToyBrick method those operate records, e.g Find/Insert
+	ToyRecordMethod map[string]struct{}
+	// method with Preload/Join and Enter/Join
+	ToyChainPreload *types.Func
+	ToyChainEnter   *types.Func
+	ToyChainJoin    *types.Func
+	ToyChainSwap    *types.Func
+	// methods push/pop model stack, include Preload/Join and Enter/Swap
+	ToyPushMethod map[string]struct{}
+	ToyPopMethod  map[string]struct{}
+	// toyorm version of analyzed module and the version rules applied
+	ToyormVersion string
+	APIRules      string
+	// toyorm.Open func and the known drivers, driver name to the packages register it
+	ToyOpen *types.Func
+	Drivers map[string][]string
+	// import paths of the whole program, nil when it is unknown, see ProgramImports
+	ProgramImports map[string]bool
+	// unsafe.Offsetof func
+	TypOffsetof *types.Builtin
+	// type wtih toyorm.FieldSelection
+	TypFieldSelection types.Type
+	// condition operators toyorm supported, operator to its value kind
+	ConditionOperators map[string]conditionValueKind
+
+	// *ToyBrick args pass to function parameter, PrevParamSites is collected by previous walk
+	ParamSites     map[*types.Var][]BrickSite
+	PrevParamSites map[*types.Var][]BrickSite
+	// *ToyBrick results return from function, PrevResultSites is collected by previous walk
+	ResultSites     map[*types.Var][]BrickSite
+	PrevResultSites map[*types.Var][]BrickSite
+	// *ToyBrick store in struct field, map or slice, Prev* is collected by previous walk
+	FieldSites     map[*types.Var][]BrickSite
+	PrevFieldSites map[*types.Var][]BrickSite
+	ElemSites      map[types.Object][]ElemSite
+	PrevElemSites  map[types.Object][]ElemSite
+	// the function current walk in
+	Func *types.Func
+	// brick contexts at unlabeled break/continue, they join the context after loop or switch
+	Breaks    *[]*Walker
+	Continues *[]*Walker
+
+	AllExpr     map[ast.Expr]struct{}
+	CheckedExpr map[ast.Expr]struct{}
+	ErrorExpr   map[ast.Expr][]error
+	// warnings found by walker, e.g Find run inside Preload context
+	WarningExpr map[ast.Expr][]error
+	// errors suppressed by //toy-doctor:ignore directives
+	IgnoredExpr map[ast.Expr][]IgnoredError
+	Ignores     []*IgnoreDirective
+	// number of findings recorded in baseline
+	BaselineCount int
+
+	Verbose bool
+	// report the ignored errors too
+	ShowIgnored bool
+}
+
+// copy the brick context, the check results are shared
+func (w *Walker) copy() *Walker {
+	newt := *w
+	newt.BrickIdentCache = map[types.Object]TypesStructList{}
+	for key := range w.BrickIdentCache {
+		newt.BrickIdentCache[key] = w.BrickIdentCache[key]
+	}
+	newt.BrickAmbiguous = map[types.Object][]TypesStructList{}
+	for key := range w.BrickAmbiguous {
+		newt.BrickAmbiguous[key] = w.BrickAmbiguous[key]
+	}
+	newt.MethodValues = map[types.Object]MethodValue{}
+	for key := range w.MethodValues {
+		newt.MethodValues[key] = w.MethodValues[key]
+	}
+	return &newt
+}
+
+// max walk times to spread brick context between functions
+const maxWalkPass = 8
+
+// Walk check all files, files are walked repeatedly until the brick context of function parameters/results
+// and struct fields/containers stop change
+func (w *Walker) Walk() {
+	for i := 0; i < maxWalkPass; i++ {
+		w.PrevParamSites, w.PrevResultSites = w.ParamSites, w.ResultSites
+		w.PrevFieldSites, w.PrevElemSites = w.FieldSites, w.ElemSites
+		w.reset()
+		for _, file := range w.Files {
+			ast.Walk(w, file)
+		}
+		if sitesChanged(w.PrevParamSites, w.ParamSites) == false && sitesChanged(w.PrevResultSites, w.ResultSites) == false &&
+			sitesChanged(w.PrevFieldSites, w.FieldSites) == false && elemSitesChanged(w.PrevElemSites, w.ElemSites) == false {
+			break
+		}
+	}
+	w.applyIgnores()
+}
+
+// clean all check result before walk
+func (w *Walker) reset() {
+	w.BrickIdentCache = map[types.Object]TypesStructList{}
+	w.BrickAmbiguous = map[types.Object][]TypesStructList{}
+	w.MethodValues = map[types.Object]MethodValue{}
+	w.BrickCallCache = map[*ast.CallExpr]TypesStructList{}
+	w.ParamSites = map[*types.Var][]BrickSite{}
+	w.ResultSites = map[*types.Var][]BrickSite{}
+	w.FieldSites = map[*types.Var][]BrickSite{}
+	w.ElemSites = map[types.Object][]ElemSite{}
+	w.AllExpr = map[ast.Expr]struct{}{}
+	w.CheckedExpr = map[ast.Expr]struct{}{}
+	w.ErrorExpr = map[ast.Expr][]error{}
+	w.WarningExpr = map[ast.Expr][]error{}
+}
+
+func (w *Walker) Report() string {
+	// sort expr by position
+	var exprs []ast.Expr
+	for expr := range w.CheckedExpr {
+		exprs = append(exprs, expr)
+	}
+	sort.Slice(exprs, func(i, j int) bool {
+		return exprs[i].Pos() < exprs[j].Pos()
+	})
+	s := ""
+	for _, e := range exprs {
+		if w.Verbose {
+			if len(w.ErrorExpr[e]) != 0 {
+				s += fmt.Sprintf("%s has error:\n", relPosition(w.FS, e.Pos()))
+			} else {
+				s += fmt.Sprintf("%s ok\n", relPosition(w.FS, e.Pos()))
+			}
+		}
+		for _, err := range w.ErrorExpr[e] {
+			s += fmt.Sprintf("\t%s\n", err)
+		}
+		if w.ShowIgnored {
+			for _, ignored := range w.IgnoredExpr[e] {
+				s += fmt.Sprintf("\t%s (ignored: %s)\n", ignored.Diagnostic, ignored.Directive.Reason)
+			}
+		}
+	}
+	return s
+}
+
+// WriteCover write coverage profile blocks without the mode line, so multiple walker can share one profile
+func (w *Walker) WriteCover(out io.Writer) error {
+	for expr := range w.AllExpr {
+		pos := w.FS.Position(expr.Pos())
+		end := w.FS.Position(expr.End())
+		_, ok := w.CheckedExpr[expr]
+		_, err := fmt.Fprintf(out, "%s:%d.%d,%d.%d %d %d\n",
+			joinPoint(w.Pkg.Path(), coverFileName(pos.Filename)), pos.Line, pos.Column, end.Line, end.Column, 1, b2i(ok))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Walker) cacheToyorm(spec *ast.ImportSpec) {
+	if spec.Path.Value == "\"github.com/bigpigeon/toyorm\"" {
+		w.Toyorm = true
+	}
+}
+
+func (w *Walker) getIdentMapWithBrickVar(spec *ast.ValueSpec) {
+	// ident only map one result call expr or other ident
+	identMap := map[*ast.Ident]ast.Expr{}
+	// j use to index rhs
+	j := 0
+	for i := range spec.Values {
+		switch x := spec.Values[i].(type) {
+		case *ast.CallExpr:
+			if sign, ok := w.Info.Types[x.Fun].Type.(*types.Signature); ok {
+				if sign.Results().Len() == 1 {
+					identMap[spec.Names[i]] = x
+				} else if len(spec.Values) == 1 {
+					var lhs []ast.Expr
+					for _, name := range spec.Names {
+						lhs = append(lhs, name)
+					}
+					w.cacheTupleBrickIdent(lhs, x)
+				}
+				j += sign.Results().Len()
+			}
+		default:
+			identMap[spec.Names[i]] = x
+			j++
+		}
+	}
+	w.cacheBrickIdent(identMap)
+}
+
+func (w *Walker) getIdentMapWIthBrickAssign(stmt *ast.AssignStmt) {
+	if len(stmt.Lhs) == len(stmt.Rhs) {
+		for i, lhs := range stmt.Lhs {
+			if index, ok := lhs.(*ast.IndexExpr); ok {
+				w.cacheIndexAssign(index, stmt.Rhs[i])
+			}
+		}
+	}
+	// ident only map one result call expr or other ident
+	identMap := map[*ast.Ident]ast.Expr{}
+	// j use to index rhs
+	j := 0
+	for i := range stmt.Rhs {
+		switch x := stmt.Rhs[i].(type) {
+		case *ast.CallExpr:
+			if sign, ok := w.Info.Types[x.Fun].Type.(*types.Signature); ok {
+				if sign.Results().Len() == 1 {
+					if lhIdent := getIdent(stmt.Lhs[i]); lhIdent != nil {
+						identMap[lhIdent] = x
+					}
+				} else if len(stmt.Rhs) == 1 {
+					w.cacheTupleBrickIdent(stmt.Lhs, x)
+					for _, lhs := range stmt.Lhs {
+						if lhIdent := getIdent(lhs); lhIdent != nil {
+							w.cacheMethodValue(lhIdent, nil)
+						}
+					}
+				}
+				j += sign.Results().Len()
+			}
+		default:
+			if lhIdent := getIdent(stmt.Lhs[i]); lhIdent != nil {
+				identMap[lhIdent] = x
+			}
+			j++
+		}
+	}
+	w.cacheBrickIdent(identMap)
+}
+
+func (w *Walker) cacheBrickIdent(identMap map[*ast.Ident]ast.Expr) {
+	toyBrickType := w.ToyModel.Type().(*types.Signature).Results().At(0).Type()
+	for lhIdent, expr := range identMap {
+		w.cacheMethodValue(lhIdent, expr)
+		w.cacheContainerAssign(w.exprObject(lhIdent), expr)
+		// token is =, obj in w.Info.User, otherwise in w.Info.Defs
+		var lhObj types.Object
+		if obj, ok := w.Info.Uses[lhIdent]; ok && obj.Type().String() == toyBrickType.String() {
+			lhObj = obj
+		} else if obj, ok := w.Info.Defs[lhIdent]; ok && obj != nil && obj.Type().String() == toyBrickType.String() {
+			lhObj = obj
+		}
+		if lhObj != nil {
+			// if rhs is ToyBrick Chain function
+			if call, ok := expr.(*ast.CallExpr); ok && w.Info.Types[call].Type.String() == toyBrickType.String() {
+				w.setBrickContext(lhObj, w.checkCallExpr(call))
+			} else if index, ok := expr.(*ast.IndexExpr); ok {
+				// if rhs is *ToyBrick in map or slice
+				ctx, ctxList := w.elemContext(index)
+				w.setBrickContexts(lhObj, ctx, ctxList)
+			} else if ident := getIdent(expr); ident != nil {
+				// if rhs is other *ToyBrick
+				w.copyBrickContext(lhObj, w.Info.Uses[ident])
+			}
+			w.recordFieldSite(lhObj, expr, w.BrickIdentCache[lhObj])
+		}
+	}
+}
+
+func (w *Walker) IsBrickChain(obj types.Object) bool {
+	if _, ok := w.ToyChainMethod[obj.String()]; ok {
+		return true
+	}
+	return false
+}
+
+// check all ToyBrick chain syntax
+// e.g
+// brick := toy.Model(Product{}).Preload(Offsetof(Product{}.Detail))  ............ ok, Preload struct same as Model struct
+// brick := toy.Model(Product{}).Preload(Offsetof(User{}.Detail)) ................ error, Preload struct not match Model struct
+func (w *Walker) ArgsCheck(mType *types.Named, args ...ast.Expr) {
+	for _, expr := range args {
+		switch x := expr.(type) {
+		case *ast.CompositeLit:
+			if _, ok := x.Type.(*ast.MapType); ok {
+				w.ArgsCheck(mType, x.Elts...)
+			}
+		case *ast.KeyValueExpr:
+			w.ArgsCheck(mType, x.Key)
+		case *ast.BasicLit:
+			if x.Kind == token.STRING {
+				w.CheckedExpr[x] = struct{}{}
+				fieldMap, err := getStructFieldMap(mType.Underlying().(*types.Struct), w.FS)
+				if err != nil {
+					w.ErrorExpr[x] = append(w.ErrorExpr[x], err)
+					break
+				}
+				name, err := strconv.Unquote(x.Value)
+				if err != nil {
+					break
+				}
+				if _, ok := fieldMap[name]; ok == false {
+					w.ErrorExpr[x] = append(w.ErrorExpr[x], ErrInvalidField{
+						FileSet:     w.FS,
+						Source:      mType,
+						Expr:        expr,
+						Suggestions: fieldSuggestions(name, fieldMap),
+					})
+				}
+			}
+		case *ast.CallExpr:
+			var obj types.Object
+			var ok bool
+			switch y := x.Fun.(type) {
+			case *ast.Ident:
+				obj, ok = w.Info.Uses[y]
+			case *ast.SelectorExpr:
+				obj, ok = w.Info.Uses[y.Sel]
+			}
+
+			if ok {
+				if obj.String() == w.TypOffsetof.String() {
+					w.CheckedExpr[x] = struct{}{}
+					arg := x.Args[0]
+					cType := getTypesStruct(w.Info.Types[arg.(*ast.SelectorExpr).X].Type)
+					if cType != mType {
+						w.ErrorExpr[x] = append(w.ErrorExpr[x], ErrDifferentStruct{w.FS, mType, arg})
+					}
+				}
+			}
+		}
+	}
+}
+
+// check field must be struct
+func (w *Walker) checkStructField(field ast.Expr, current *types.Struct) *types.Named {
+	switch x := field.(type) {
+	case *ast.CallExpr:
+		var obj types.Object
+		var ok bool
+		switch y := x.Fun.(type) {
+		case *ast.SelectorExpr:
+			obj, ok = w.Info.Uses[y.Sel]
+		case *ast.Ident:
+			obj, ok = w.Info.Uses[y]
+		}
+		if ok && w.TypOffsetof.String() == obj.String() {
+			w.CheckedExpr[x] = struct{}{}
+			arg := x.Args[0].(*ast.SelectorExpr)
+			if structType := getTypesStruct(w.Info.Selections[arg].Type()); structType != nil {
+				return structType
+			}
+			w.ErrorExpr[x] = append(w.ErrorExpr[x], ErrInvalidStructField{w.FS, arg.Sel})
+		}
+
+	case *ast.BasicLit:
+		if x.Kind == token.STRING {
+			w.CheckedExpr[x] = struct{}{}
+			fieldMap, err := getStructFieldMap(current, w.FS)
+			if err != nil {
+				w.ErrorExpr[x] = append(w.ErrorExpr[x], err)
+				return nil
+			}
+			name, err := strconv.Unquote(x.Value)
+			if err != nil {
+				return nil
+			}
+			if field, ok := fieldMap[name]; ok {
+				if structType := getTypesStruct(field.Type()); structType != nil {
+					return structType
+				}
+			}
+			w.ErrorExpr[x] = append(w.ErrorExpr[x], ErrInvalidStructField{w.FS, x})
+		}
+	}
+	return nil
+}
+
+// get all toyorm.FieldSelection args in function
+func (w *Walker) getFieldSelection(call *ast.CallExpr) []ast.Expr {
+	var args []ast.Expr
+	callTyp := w.Info.Types[call.Fun].Type.(*types.Signature)
+
+	if callTyp.Variadic() {
+		for i := 0; i < callTyp.Params().Len()-1; i++ {
+			if callTyp.Params().At(i).Type().String() == w.TypFieldSelection.String() {
+				args = append(args, call.Args[i])
+			}
+		}
+		// in variadic arg must more than one
+		lastArg := callTyp.Params().At(callTyp.Params().Len() - 1)
+		// variadic arg must be slice type
+		if lastArg.Type().(*types.Slice).Elem().String() == w.TypFieldSelection.String() {
+			for i := callTyp.Params().Len() - 1; i < len(call.Args); i++ {
+				args = append(args, call.Args[i])
+			}
+		}
+	} else {
+		for i := 0; i < callTyp.Params().Len(); i++ {
+			if callTyp.Params().At(i).Type().String() == w.TypFieldSelection.String() {
+				args = append(args, call.Args[i])
+			}
+		}
+	}
+
+	return args
+}
+
+func (w *Walker) markExpr(args ...ast.Expr) {
+	for _, arg := range args {
+		w.AllExpr[arg] = struct{}{}
+	}
+}
+
+// to check brick chain syntax
+// output error when chain model different source model
+// error e.g brick.Model(Product{}).OrderBy(Offsetof(User{}.Data))
+func (w *Walker) checkCallExpr(call *ast.CallExpr) TypesStructList {
+	var ctx TypesStructList
+	// for the declarations
+	// brick := productQuery(toy).OrderBy(...)
+	if ctx = w.resultContext(call, 0); len(ctx) != 0 {
+		w.BrickCallCache[call] = ctx
+		return ctx
+	}
+	// prevent duplicate check
+	if checkedList, ok := w.BrickCallCache[call]; ok {
+		return checkedList
+	}
+	var methodObj types.Object
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		if selection := w.Info.Selections[fun]; selection != nil && selection.Kind() == types.MethodExpr {
+			// for the declarations
+			// (*toyorm.ToyBrick).OrderBy(brick, ...)
+			methodObj = selection.Obj()
+			if len(call.Args) > 0 {
+				ctx = w.exprContext(call.Args[0])
+			}
+			break
+		}
+		methodObj = w.Info.Uses[fun.Sel]
+		// get previous ctx
+
+		// for the declarations
+		// toy := ToyOpen("sqlite3", "")
+		// brick = toy.Model(&Product{}).Debug.Where()...
+		if selCall, ok := fun.X.(*ast.CallExpr); ok {
+			ctx = w.checkCallExpr(selCall)
+		} else if index, ok := fun.X.(*ast.IndexExpr); ok {
+			elemCtx, ctxList := w.elemContext(index)
+			ctx = w.checkAmbiguous(index, elemCtx, ctxList)
+		} else if selIdent := getIdent(fun.X); selIdent != nil {
+			ctx = w.identContext(selIdent)
+		}
+	case *ast.Ident:
+		// for the declarations
+		// method := brick.Limit
+		// method(2)
+		if method, ok := w.MethodValues[w.Info.Uses[fun]]; ok {
+			methodObj = method.Method
+			if method.Expr {
+				if len(call.Args) > 0 {
+					ctx = w.exprContext(call.Args[0])
+				}
+			} else {
+				ctx = method.Ctx
+			}
+		}
+	}
+	if methodObj == nil {
+		return ctx
+	}
+
+	if w.ToyModel.String() == methodObj.String() {
+		arg := call.Args[len(call.Args)-1]
+		if _type, ok := w.Info.Types[arg]; ok {
+			// model is not a struct, e.g interface{} value, the brick can't be checked
+			if sType := getTypesStruct(_type.Type); sType != nil {
+				w.checkPrimaryKey(arg, sType)
+				ctx = append(ctx.Copy(), sType)
+			} else {
+				ctx = nil
+			}
+		}
+	} else {
+		if w.IsBrickChain(methodObj) {
+			args := w.getFieldSelection(call)
+			w.markExpr(args...)
+			var mType *types.Named
+			if len(ctx) > 0 {
+				mType = ctx[len(ctx)-1]
+				w.ArgsCheck(mType, args...)
+				w.noteChainField(call, ctx, args...)
+			}
+			w.checkCondition(call, mType)
+		} else if w.IsPushMethod(methodObj) {
+			args := w.getFieldSelection(call)
+			w.markExpr(args...)
+			if len(ctx) > 0 && len(args) > 0 {
+				w.ArgsCheck(ctx[len(ctx)-1], args...)
+				w.noteChainField(call, ctx, args...)
+				// check Preload field type
+				if fieldStruct := w.checkStructField(args[0], ctx[len(ctx)-1].Underlying().(*types.Struct)); fieldStruct != nil {
+					if methodObj.String() == w.ToyChainPreload.String() {
+						w.checkRelation(args[0], ctx[len(ctx)-1], fieldStruct)
+					}
+					ctx = append(ctx.Copy(), fieldStruct)
+				} else {
+					ctx = nil
+				}
+			}
+		} else if w.IsPopMethod(methodObj) {
+			ctx = w.checkPop(call, methodObj, ctx)
+		} else {
+			if w.IsRecordMethod(methodObj) {
+				w.checkRecord(call, ctx)
+			}
+			w.checkNested(call, methodObj, ctx)
+		}
+	}
+
+	// this call was checked
+	w.BrickCallCache[call] = ctx
+	return ctx
+}
+
+func newWalker(fileSet *token.FileSet, pkg *types.Package, files []*ast.File, info *types.Info, imp types.Importer, api *APIDescriptor, verbose bool) (*Walker, error) {
+	walker := &Walker{
+		FS:              fileSet,
+		Pkg:             pkg,
+		Files:           files,
+		Info:            info,
+		Importer:        imp,
+		BrickIdentCache: map[types.Object]TypesStructList{},
+		BrickAmbiguous:  map[types.Object][]TypesStructList{},
+		MethodValues:    map[types.Object]MethodValue{},
+		BrickCallCache:  map[*ast.CallExpr]TypesStructList{},
+		ParamSites:      map[*types.Var][]BrickSite{},
+		ResultSites:     map[*types.Var][]BrickSite{},
+		FieldSites:      map[*types.Var][]BrickSite{},
+		ElemSites:       map[types.Object][]ElemSite{},
+		ToyChainMethod:  map[string]struct{}{},
+		ToyRecordMethod: map[string]struct{}{},
+		ToyPushMethod:   map[string]struct{}{},
+		ToyPopMethod:    map[string]struct{}{},
+		AllExpr:         map[ast.Expr]struct{}{},
+		CheckedExpr:     map[ast.Expr]struct{}{},
+		ErrorExpr:       map[ast.Expr][]error{},
+		WarningExpr:     map[ast.Expr][]error{},
+		IgnoredExpr:     map[ast.Expr][]IgnoredError{},
+		Drivers:         DefaultDrivers,
+		Verbose:         verbose,
+	}
+	if err := walker.resolveAPI(api); err != nil {
+		return nil, err
+	}
+	return walker, nil
+}
+
+// NewWalker type-check files with source importer and create a walker for them
+func NewWalker(fileSet *token.FileSet, path string, files []*ast.File, verbose bool) (*Walker, error) {
+	info := &types.Info{
+		Uses:       map[*ast.Ident]types.Object{},
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:     map[ast.Node]*types.Scope{},
+		Defs:       make(map[*ast.Ident]types.Object),
+	}
+	imp := importer.For("source", nil)
+	config := types.Config{Importer: imp, FakeImportC: true}
+	pkg, err := config.Check(path, fileSet, files, info)
+	if err != nil {
+		return nil, err
+	}
+	// unknown toyorm version use the latest rules
+	return newVersionWalker(fileSet, pkg, files, info, imp, nil, "", verbose)
+}
+
+// NewPackageWalker create a walker for package loaded by go/packages,
+// the package must be loaded with syntax, types, dependencies and module,
+// nil api use the rules of toyorm version in go.mod
+func NewPackageWalker(pkg *packages.Package, api *APIDescriptor, verbose bool) (*Walker, error) {
+	return newVersionWalker(pkg.Fset, pkg.Types, pkg.Syntax, pkg.TypesInfo, typesImporter(pkg.Types), api, packageToyormVersion(pkg), verbose)
+}
+
+// create walker with the rules of toyorm version when api is nil
+func newVersionWalker(fileSet *token.FileSet, pkg *types.Package, files []*ast.File, info *types.Info, imp types.Importer, api *APIDescriptor, version string, verbose bool) (*Walker, error) {
+	rules := "api descriptor"
+	if api == nil {
+		versionAPI := APIForVersion(version)
+		api, rules = &versionAPI.API, versionAPI.String()
+	}
+	walker, err := newWalker(fileSet, pkg, files, info, imp, api, verbose)
+	if err != nil {
+		return nil, err
+	}
+	walker.ToyormVersion, walker.APIRules = version, rules
+	return walker, nil
+}
+
+func (w *Walker) Visit(node ast.Node) ast.Visitor {
+	switch x := node.(type) {
+	case *ast.ImportSpec:
+		w.cacheToyorm(x)
+	//if x.Tok != token.IMPORT {
+	//	return nil
+	//}
+	case *ast.ValueSpec:
+		w.getIdentMapWithBrickVar(x)
+	case *ast.AssignStmt:
+		w.getIdentMapWIthBrickAssign(x)
+		w.checkDiscarded(x)
+	case *ast.ExprStmt:
+		w.checkDiscarded(x)
+	case *ast.FuncDecl:
+		w.cacheParamBrick(x)
+		fw := w.copy()
+		fw.Func, _ = w.Info.Defs[x.Name].(*types.Func)
+		fw.Breaks, fw.Continues = nil, nil
+		return fw
+	case *ast.FuncLit:
+		// return/break in function literal not belong to current function
+		fw := w.copy()
+		fw.Func = nil
+		fw.Breaks, fw.Continues = nil, nil
+		return fw
+	case *ast.BranchStmt:
+		w.recordBranch(x)
+	case *ast.ReturnStmt:
+		w.recordResultSites(x)
+	case *ast.CallExpr:
+		w.checkCallExpr(x)
+		w.checkDriver(x)
+		w.recordParamSites(x)
+	case *ast.IfStmt:
+		w.walkIf(x)
+		return nil
+	case *ast.SwitchStmt:
+		w.walkSwitch(x.Init, x.Tag, x.Body)
+		return nil
+	case *ast.TypeSwitchStmt:
+		w.walkSwitch(x.Init, x.Assign, x.Body)
+		return nil
+	case *ast.SelectStmt:
+		w.walkSwitch(nil, nil, x.Body)
+		return nil
+	case *ast.ForStmt:
+		w.walkLoop(x.Init, x.Cond, x.Post, x.Body)
+		return nil
+	case *ast.StructType:
+		w.checkStructTags(x)
+	case *ast.CompositeLit:
+		w.cacheStructLit(x)
+	case *ast.RangeStmt:
+		w.cacheRangeBrick(x)
+		w.walkLoop(nil, x.X, nil, x.Body, x.Key, x.Value)
+		return nil
+	}
+	return w
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package doctor
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// the api objects are resolved from the toyorm that the program imported
+func TestWalkProgramAPI(t *testing.T) {
+	walk := walkTestFile(t, "testdata/struct_notmatch.go")
+	var toyPkg *types.Package
+	for _, pkg := range walk.Pkg.Imports() {
+		if pkg.Path() == ToyormPath {
+			toyPkg = pkg
+		}
+	}
+	if assert.NotNil(t, toyPkg) == false {
+		return
+	}
+	toyType, err := lookupNamed(toyPkg, "Toy")
+	assert.Nil(t, err)
+	brickType, err := lookupNamed(toyPkg, "ToyBrick")
+	assert.Nil(t, err)
+	model, err := lookupMethod(types.NewPointer(toyType), "Model")
+	assert.Nil(t, err)
+	assert.True(t, walk.ToyModel == model)
+	preload, err := lookupMethod(types.NewPointer(brickType), "Preload")
+	assert.Nil(t, err)
+	assert.True(t, walk.ToyChainPreload == preload)
+	orderBy, err := lookupMethod(types.NewPointer(brickType), "OrderBy")
+	assert.Nil(t, err)
+	assert.True(t, walk.IsBrickChain(orderBy))
+
+	// the same object is used by the program
+	used := false
+	for _, obj := range walk.Info.Uses {
+		used = used || obj == walk.ToyModel
+	}
+	assert.True(t, used)
+}
+
+func TestWalk(t *testing.T) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, "testdata/struct_notmatch.go", nil, 0)
+	assert.Nil(t, err)
+	walk, err := NewWalker(fs, ".", []*ast.File{file}, true)
+	assert.Nil(t, err)
+	walk.Walk()
+	t.Logf("\n%s\n", walk.Report())
+}
+
+func walkTestFile(t *testing.T, filename string) *Walker {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, filename, nil, parser.ParseComments)
+	assert.Nil(t, err)
+	walk, err := NewWalker(fs, ".", []*ast.File{file}, true)
+	assert.Nil(t, err)
+	walk.Walk()
+	t.Logf("\n%s\n", walk.Report())
+	return walk
+}
+
+func diagnosticRules(walk *Walker) map[string]int {
+	rules := map[string]int{}
+	for _, d := range walk.Diagnostics() {
+		rules[d.Rule()]++
+	}
+	return rules
+}
+
+func TestWalkParam(t *testing.T) {
+	walk := walkTestFile(t, "testdata/param.go")
+	rules := diagnosticRules(walk)
+	// OrderProduct receive brick from OrderProductByName
+	assert.Equal(t, rules[RuleDifferentStruct], 1)
+	assert.Equal(t, rules[RuleParamConflict], 1)
+}
+
+func TestWalkResult(t *testing.T) {
+	walk := walkTestFile(t, "testdata/result.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleDifferentStruct], 3)
+}
+
+func TestWalkFlow(t *testing.T) {
+	walk := walkTestFile(t, "testdata/flow.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleDifferentStruct], 1)
+	// the loop brick is ambiguous in both OrderBy and Preload,
+	// fallthrough and break in switch are ambiguous too,
+	// so are break and continue inside if in a loop
+	assert.Equal(t, rules[RuleAmbiguousBrick], 7)
+}
+
+func TestWalkMethodValue(t *testing.T) {
+	walk := walkTestFile(t, "testdata/method.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleDifferentStruct], 4)
+	// the copied method value is checked, the reassigned one and the one assigned in other branch aren't
+	var lines []int
+	for _, d := range walk.Diagnostics() {
+		if pos := walk.FS.Position(d.Pos()); d.Rule() == RuleDifferentStruct && pos.Line > 54 {
+			lines = append(lines, pos.Line)
+		}
+	}
+	assert.Equal(t, lines, []int{59})
+}
+
+func TestWalkStore(t *testing.T) {
+	walk := walkTestFile(t, "testdata/store.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleDifferentStruct], 4)
+	assert.Equal(t, rules[RuleAmbiguousBrick], 1)
+}
+
+func TestWalkRecord(t *testing.T) {
+	walk := walkTestFile(t, "testdata/record.go")
+	rules := diagnosticRules(walk)
+	assert.Equal(t, rules[RuleDifferentRecord], 5)
+}
+
+func TestWalkDiscard(t *testing.T) {
+	walk := walkTestFile(t, "testdata/discard.go")
+	var fixes []string
+	for _, d := range walk.Diagnostics() {
+		assert.Equal(t, d.Rule(), RuleDiscardedResult)
+		for _, fix := range walk.SuggestedFixes(d) {
+			edit := fix.TextEdits[0]
+			fixes = append(fixes, fmt.Sprintf("%s %q", walk.FS.Position(edit.Pos), edit.NewText))
+		}
+	}
+	assert.Equal(t, diagnosticRules(walk)[RuleDiscardedResult], 6)
+	// limited at line 38 isn't read later
+	assert.Equal(t, fixes, []string{
+		`testdata/discard.go:22:2 "brick = "`,
+		`testdata/discard.go:23:2 "brick = "`,
+		`testdata/discard.go:25:2 "brick = "`,
+		`testdata/discard.go:26:2 "brick"`,
+	})
+}
+
+func TestWalkNested(t *testing.T) {
+	walk := walkTestFile(t, "testdata/nested.go")
+	var errs, warnings []string
+	for _, d := range walk.Diagnostics() {
+		errs = append(errs, d.Error())
+	}
+	for _, d := range walk.Warnings() {
+		warnings = append(warnings, d.Error())
+	}
+	assert.Equal(t, errs, []string{
+		"testdata/nested.go:33:10 Enter pop past the root model Product, no Preload/Join to leave",
+		"testdata/nested.go:34:10 Swap pop past the root model Product, no Preload/Join to leave",
+	})
+	assert.Equal(t, warnings, []string{
+		"testdata/nested.go:36:2 Count run inside the nested context of Detail, use Enter()/Swap() back to Product first",
+		"testdata/nested.go:39:2 Find run inside the nested context of Detail, use Enter()/Swap() back to Product first",
+	})
+}
+
+func TestWalkRelation(t *testing.T) {
+	walk := walkTestFile(t, "testdata/relation.go")
+	var errs []string
+	for _, d := range walk.Diagnostics() {
+		if d.Rule() == RuleInvalidRelation {
+			errs = append(errs, d.Error())
+		}
+	}
+	assert.Equal(t, errs, []string{
+		"testdata/relation.go:81:20 preload Product.Comments have no relation, need Comment.PID uint32 (one to many)",
+		"testdata/relation.go:82:20 preload Product.Shop have no relation, need Shop.ProductID uint32 (one to one) or Product.ShopID int (belong to)",
+		"testdata/relation.go:83:20 preload Product.Extra have no relation, need Extra.ProductID uint32 (one to one)",
+	})
+}
+
+func TestWalkForeignKey(t *testing.T) {
+	walk := walkTestFile(t, "testdata/foreignkey.go")
+	var errs []string
+	for _, d := range walk.Diagnostics() {
+		errs = append(errs, d.Error())
+	}
+	assert.Equal(t, errs, []string{
+		// reported once at declaration
+		"testdata/foreignkey.go:52:6 model Tag have no primary key, add ID field or primary key tag",
+		"testdata/foreignkey.go:58:24 Detail.ProductID type int not match primary key Product.ID uint32 (one to one)",
+		"testdata/foreignkey.go:59:24 Product.OwnerID type int not match primary key User.ID uint32 (belong to)",
+		"testdata/foreignkey.go:60:24 Comment.ProductID type string not match primary key Product.ID uint32 (one to many)",
+	})
+	assert.Contains(t, walk.Report(), "\ttestdata/foreignkey.go:52:6 model Tag have no primary key")
+}
+
+func TestForeignKeyCompatible(t *testing.T) {
+	uint32Type := types.Typ[types.Uint32]
+	key := types.NewNamed(types.NewTypeName(token.NoPos, nil, "ProductKey", nil), uint32Type, nil)
+	assert.True(t, foreignKeyCompatible(uint32Type, uint32Type))
+	assert.True(t, foreignKeyCompatible(types.NewPointer(uint32Type), uint32Type))
+	assert.True(t, foreignKeyCompatible(key, uint32Type))
+	// convertible integer isn't the same key
+	assert.False(t, foreignKeyCompatible(types.Typ[types.Int], uint32Type))
+	assert.False(t, foreignKeyCompatible(types.Typ[types.Uint64], uint32Type))
+	assert.False(t, foreignKeyCompatible(types.Typ[types.String], uint32Type))
+}
@@ -13,5 +13,19 @@ func ExampleCheck() {
 	}
 	Main(args)
 	// Output:
+	// 	exampledata/main.go:27:2 result of Enter is discarded, it return a new brick
+	// 	exampledata/main.go:39:26 driver "sqlite3" is not registered, import _ "github.com/mattn/go-sqlite3"
+	// 	exampledata/main.go:55:33 type must same as exampledata/main.go:20:6
+}
+
+// run likes: toy-doctor ./exampledata/...
+func Example_packages() {
+	args := []string{
+		"./exampledata/...",
+	}
+	Main(args)
+	// Output:
+	// 	exampledata/main.go:27:2 result of Enter is discarded, it return a new brick
+	// 	exampledata/main.go:39:26 driver "sqlite3" is not registered, import _ "github.com/mattn/go-sqlite3"
 	// 	exampledata/main.go:55:33 type must same as exampledata/main.go:20:6
 }
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/bigpigeon/toy-doctor/doctor"
+)
+
+// text edit with file offset
+type fileEdit struct {
+	Start, End int
+	NewText    []byte
+}
+
+// apply the first suggested fix of all diagnostics and write back to files,
+// the fix overlap with another fix is skipped, return the number of fixed diagnostics
+func applyFixes(walkers []*doctor.Walker) (int, error) {
+	fileEdits := map[string][]fileEdit{}
+	fixed := 0
+	for _, walk := range walkers {
+		for _, d := range walk.Diagnostics() {
+			fixes := walk.SuggestedFixes(d)
+			if len(fixes) == 0 {
+				continue
+			}
+			var edits []fileEdit
+			var filename string
+			for _, e := range fixes[0].TextEdits {
+				file := walk.FS.File(e.Pos)
+				filename = file.Name()
+				edits = append(edits, fileEdit{file.Offset(e.Pos), file.Offset(e.End), e.NewText})
+			}
+			if overlap(fileEdits[filename], edits) {
+				continue
+			}
+			fileEdits[filename] = append(fileEdits[filename], edits...)
+			fixed++
+		}
+	}
+	for filename, edits := range fileEdits {
+		if err := writeEdits(filename, edits); err != nil {
+			return fixed, err
+		}
+	}
+	return fixed, nil
+}
+
+func overlap(exists, edits []fileEdit) bool {
+	for _, a := range exists {
+		for _, b := range edits {
+			if a.Start < b.End && b.Start < a.End || a.Start == b.Start {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeEdits(filename string, edits []fileEdit) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start < edits[j].Start
+	})
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.Start])
+		buf.Write(e.NewText)
+		last = e.End
+	}
+	buf.Write(src[last:])
+	return ioutil.WriteFile(filename, buf.Bytes(), info.Mode())
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes |
+	packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps | packages.NeedModule
+
+// load all packages match patterns, e.g ./... or a directory or a list of files
+func loadPackages(patterns []string) ([]*packages.Package, error) {
+	cfg := &packages.Config{Mode: loadMode}
+	pkgs, err := packages.Load(cfg, normalizePatterns(patterns)...)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			errs = append(errs, e.Error())
+		}
+	})
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("load packages failure:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].PkgPath < pkgs[j].PkgPath
+	})
+	return pkgs, nil
+}
+
+// go list treat "exampledata/" as import path, convert local directory to ./exampledata/
+func normalizePatterns(patterns []string) []string {
+	var result []string
+	for _, p := range patterns {
+		if !filepath.IsAbs(p) && !strings.HasPrefix(p, ".") && !strings.HasSuffix(p, ".go") {
+			if info, err := os.Stat(p); err == nil && info.IsDir() {
+				p = "." + string(filepath.Separator) + p
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
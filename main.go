@@ -9,86 +9,267 @@ package main
 import (
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
 	"os"
-	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bigpigeon/toy-doctor/doctor"
 )
 
 var (
-	verbose      = flag.Bool("verbose", false, "print verbose log")
-	coverProfile = flag.String("coverprofile", "", "Write a coverage profile to the file after all check have done.")
+	verbose       = flag.Bool("verbose", false, "print verbose log")
+	coverProfile  = flag.String("coverprofile", "", "Write a coverage profile to the file after all check have done.")
+	format        = flag.String("format", "text", "Output format, text, json or sarif.")
+	showIgnored   = flag.Bool("show-ignored", false, "Report the errors suppressed by //toy-doctor:ignore too.")
+	writeBaseline = flag.String("write-baseline", "", "Write all current findings to the baseline file and exit.")
+	baselineFile  = flag.String("baseline", "", "Only report the findings not recorded in the baseline file.")
+	fix           = flag.Bool("fix", false, "Apply the suggested fixes to source files.")
+	apiFile       = flag.String("api", "", "The toyorm api descriptor file for renamed methods.")
+	driversFile   = flag.String("drivers", "", "The json file of extra database/sql drivers, driver name to the packages register it.")
 )
 
 func Usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-	fmt.Fprint(os.Stderr, "\ttoy-doctor [flags] [directory]\n")
+	fmt.Fprint(os.Stderr, "\ttoy-doctor [flags] [packages] # e.g ./... or a directory\n")
 	fmt.Fprint(os.Stderr, "\ttoy-doctor [flags] files... # Must be a single package\n")
 	fmt.Fprint(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 }
 
-func Main(args []string) {
+// exit code of toy-doctor
+const (
+	exitClean       = 0 // no problem found
+	exitDiagnostics = 1 // found diagnostics
+	exitFailure     = 2 // load/type-check failure or output error
+)
+
+func Main(args []string) int {
 	if len(args) == 0 {
 		// Default: process whole package in current directory.
 		args = []string{"."}
 	}
 
-	// Parse the package once.
-	var (
-		dir   string
-		files []*ast.File
-		walk  *Walker
-		err   error
-	)
-	fs := token.NewFileSet()
+	if *format != "text" && *format != "json" && *format != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flag.Usage()
+		return exitFailure
+	}
 
-	if len(args) == 1 && isDirectory(args[0]) {
-		dir = args[0]
-		pkgMap, err := parser.ParseDir(fs, dir, nil, 0)
-		if err != nil {
-			panic(err)
+	var baseline *doctor.Baseline
+	if *baselineFile != "" {
+		var err error
+		if baseline, err = readBaseline(*baselineFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
 		}
-		for _, pkg := range pkgMap {
-			for _, f := range pkg.Files {
-				files = append(files, f)
-			}
+	}
+
+	var api *doctor.APIDescriptor
+	if *apiFile != "" {
+		var err error
+		if api, err = doctor.LoadAPIDescriptorFile(*apiFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
 		}
-	} else {
-		dir = filepath.Dir(args[0])
-		for _, arg := range args {
-			f, err := parser.ParseFile(fs, arg, nil, 0)
-			if err != nil {
-				panic(err)
-			}
-			files = append(files, f)
+	}
+
+	drivers := doctor.DefaultDrivers
+	if *driversFile != "" {
+		var err error
+		if drivers, err = doctor.LoadDriversFile(*driversFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
 		}
 	}
-	walk, err = NewWalker(fs, dir, files, *verbose)
+
+	pkgs, err := loadPackages(args)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
 	}
-	for _, file := range files {
-		ast.Walk(walk, file)
+	programImports := doctor.ProgramImports(pkgs)
+	var walkers []*doctor.Walker
+	for _, pkg := range pkgs {
+		// package without toyorm have nothing to check
+		if !doctor.ImportsToyorm(pkg.Types) {
+			continue
+		}
+		walk, err := doctor.NewPackageWalker(pkg, api, *verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", pkg.PkgPath, err)
+			return exitFailure
+		}
+		walk.ShowIgnored = *showIgnored
+		walk.Drivers = drivers
+		walk.ProgramImports = programImports
+		walk.Walk()
+		walkers = append(walkers, walk)
+	}
+	// write all findings before baseline applied, so -baseline and -write-baseline can update the same file
+	if *writeBaseline != "" {
+		if err := createBaseline(*writeBaseline, walkers); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		count, _ := summary(walkers)
+		fmt.Fprintf(os.Stderr, "toy-doctor: write %d findings to %s\n", count, *writeBaseline)
+		return exitClean
+	}
+	if baseline != nil {
+		for _, walk := range walkers {
+			walk.ApplyBaseline(baseline)
+		}
+	}
+	switch *format {
+	case "json":
+		err = doctor.WriteJSON(os.Stdout, walkers)
+	case "sarif":
+		err = doctor.WriteSARIF(os.Stdout, walkers)
+	default:
+		for _, walk := range walkers {
+			fmt.Print(walk.Report())
+		}
+		fmt.Println()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
 	}
-	fmt.Println(walk.Report())
 	if *coverProfile != "" {
-		walk.reportCover(*coverProfile)
+		if err := reportCover(*coverProfile, walkers); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+	for _, walk := range walkers {
+		for _, warning := range walk.Warnings() {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+	}
+	reportRules(walkers)
+	count, line := summary(walkers)
+	fmt.Fprintln(os.Stderr, line)
+	if *fix {
+		fixed, err := applyFixes(walkers)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		fmt.Fprintf(os.Stderr, "toy-doctor: fixed %d problems\n", fixed)
+		count -= fixed
+	}
+	if baseline != nil {
+		inBaseline := 0
+		for _, walk := range walkers {
+			inBaseline += walk.BaselineCount
+		}
+		fmt.Fprintf(os.Stderr, "toy-doctor: %d findings in baseline are hidden\n", inBaseline)
 	}
+	if count != 0 {
+		return exitDiagnostics
+	}
+	return exitClean
+}
+
+// print the toyorm version rules applied, e.g "toy-doctor: toyorm v0.5.0 checked with rules of toyorm >= v0.0.0"
+func reportRules(walkers []*doctor.Walker) {
+	reported := map[string]bool{}
+	for _, walk := range walkers {
+		version := walk.ToyormVersion
+		if version == "" {
+			version = "(unknown version)"
+		}
+		line := fmt.Sprintf("toy-doctor: toyorm %s checked with rules of %s", version, walk.APIRules)
+		if !reported[line] {
+			reported[line] = true
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+}
+
+// count diagnostics by rule, e.g "toy-doctor: 3 problems (different-struct: 2, invalid-field: 1)"
+func summary(walkers []*doctor.Walker) (int, string) {
+	total := 0
+	ruleCount := map[string]int{}
+	for _, walk := range walkers {
+		for _, d := range walk.Diagnostics() {
+			ruleCount[d.Rule()]++
+			total++
+		}
+	}
+	if total == 0 {
+		return 0, "toy-doctor: no problems found"
+	}
+	var rules []string
+	for _, rule := range doctor.Rules {
+		if n, ok := ruleCount[rule.ID]; ok {
+			rules = append(rules, fmt.Sprintf("%s: %d", rule.ID, n))
+			delete(ruleCount, rule.ID)
+		}
+	}
+	// rule not in doctor.Rules
+	var others []string
+	for rule, n := range ruleCount {
+		others = append(others, fmt.Sprintf("%s: %d", rule, n))
+	}
+	sort.Strings(others)
+	rules = append(rules, others...)
+	problem := "problems"
+	if total == 1 {
+		problem = "problem"
+	}
+	return total, fmt.Sprintf("toy-doctor: %d %s (%s)", total, problem, strings.Join(rules, ", "))
 }
 
-func isDirectory(name string) bool {
-	info, err := os.Stat(name)
+func readBaseline(filename string) (*doctor.Baseline, error) {
+	f, err := os.Open(filename)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	defer f.Close()
+	return doctor.ReadBaseline(f)
+}
+
+func createBaseline(filename string, walkers []*doctor.Walker) (err error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return doctor.WriteBaseline(f, walkers)
+}
+
+// merge all walker coverage to one profile
+func reportCover(profilename string, walkers []*doctor.Walker) (err error) {
+	f, err := os.Create(profilename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	// only support set mode
+	if _, err := fmt.Fprintf(f, "mode: set\n"); err != nil {
+		return err
+	}
+	for _, walk := range walkers {
+		if err := walk.WriteCover(f); err != nil {
+			return err
+		}
 	}
-	return info.IsDir()
+	return nil
 }
 
 func main() {
 	flag.Usage = Usage
 	flag.Parse()
 	args := flag.Args()
-	Main(args)
+	os.Exit(Main(args))
 }
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018. bigpigeon. All rights reserved.
+ * Use of this source code is governed by a MIT style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/bigpigeon/toy-doctor/doctor"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMainExitCode(t *testing.T) {
+	assert.Equal(t, Main([]string{"exampledata/"}), exitDiagnostics)
+	assert.Equal(t, Main([]string{"./not_exist_dir"}), exitFailure)
+}
+
+func TestSummary(t *testing.T) {
+	count, line := summary(nil)
+	assert.Equal(t, count, 0)
+	assert.Equal(t, line, "toy-doctor: no problems found")
+}
+
+func TestApplyFixes(t *testing.T) {
+	src, err := ioutil.ReadFile("doctor/testdata/fix.go")
+	assert.Nil(t, err)
+	dir, err := ioutil.TempDir("", "toy-doctor")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "fix.go")
+	assert.Nil(t, ioutil.WriteFile(filename, src, 0644))
+
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, filename, nil, parser.ParseComments)
+	assert.Nil(t, err)
+	walk, err := doctor.NewWalker(fs, ".", []*ast.File{file}, false)
+	assert.Nil(t, err)
+	walk.Walk()
+	fixed, err := applyFixes([]*doctor.Walker{walk})
+	assert.Nil(t, err)
+	assert.Equal(t, fixed, 4)
+
+	result, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(result), "OrderBy(unsafe.Offsetof(Product{}.Name))"))
+	assert.True(t, strings.Contains(string(result), `OrderBy("cost")`))
+	assert.True(t, strings.Contains(string(result), `OrderBy("NotExist")`))
+}
+
+func TestMainRefreshBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toy-doctor")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "baseline.json")
+	defer func() { *writeBaseline, *baselineFile = "", "" }()
+
+	*writeBaseline = filename
+	assert.Equal(t, Main([]string{"exampledata/"}), exitClean)
+	expected, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	// the baseline is written before applied, existing findings are kept
+	*baselineFile = filename
+	assert.Equal(t, Main([]string{"exampledata/"}), exitClean)
+	result, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, string(result), string(expected))
+	assert.True(t, strings.Contains(string(result), `"rule"`))
+
+	*writeBaseline = ""
+	assert.Equal(t, Main([]string{"exampledata/"}), exitClean)
+}